@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -81,10 +82,31 @@ func RecupererToutesLocations() (models.IndexLocations, error) {
 	return locs, nil
 }
 
+// RecupererToutesRelations - va chercher les relations (dates + lieux) de tous les artistes d'un coup
+// utile pour la recherche avancee (nombre de concerts, dates...)
+func RecupererToutesRelations() (models.IndexRelations, error) {
+	var rels models.IndexRelations
+	err := fetchJSON(baseURL+"/relation", &rels)
+	if err != nil {
+		return rels, fmt.Errorf("impossible de recuperer les relations: %w", err)
+	}
+	return rels, nil
+}
+
 // RecupererImageArtiste - telecharge l'image d'un artiste et retourne les bytes
 // on fait ca pour afficher les images dans Fyne
 func RecupererImageArtiste(imageURL string) ([]byte, error) {
-	resp, err := httpClient.Get(imageURL)
+	return RecupererImageArtisteCtx(context.Background(), imageURL)
+}
+
+// RecupererImageArtisteCtx - pareil mais on peut abandonner le telechargement en route
+// (la grille annule les images des cards qui sont sorties de l'ecran)
+func RecupererImageArtisteCtx(ctx context.Context, imageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erreur creation requete image: %w", err)
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("erreur telechargement image: %w", err)
 	}
@@ -1,6 +1,7 @@
 package geo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -29,6 +30,44 @@ var (
 	cacheMutex  sync.RWMutex
 )
 
+// rate limit de Nominatim: 1 requete par seconde max, pour toute l'app
+// (la page d'un artiste, la comparaison et la page d'un lieu geocodent en meme temps,
+// chacune avec son petit sleep ca faisait plusieurs requetes par seconde)
+const intervalleNominatim = 1100 * time.Millisecond
+
+var (
+	prochaineRequete time.Time // le premier moment ou on a le droit de refaire une requete
+	limiteMutex      sync.Mutex
+)
+
+// attendreTour - reserve le prochain creneau libre pour une requete Nominatim et attend qu'il arrive
+// renvoie l'erreur du contexte s'il est annule avant (la page a ete quittee); dans ce cas on rend
+// le creneau s'il est toujours le dernier reserve, sinon des allers-retours rapides entre les pages
+// repousseraient toutes les requetes suivantes pour rien
+func attendreTour(ctx context.Context) error {
+	limiteMutex.Lock()
+	creneau := time.Now()
+	if prochaineRequete.After(creneau) {
+		creneau = prochaineRequete
+	}
+	prochaineRequete = creneau.Add(intervalleNominatim)
+	limiteMutex.Unlock()
+
+	attente := time.NewTimer(time.Until(creneau))
+	defer attente.Stop()
+	select {
+	case <-attente.C:
+		return nil
+	case <-ctx.Done():
+		limiteMutex.Lock()
+		if prochaineRequete.Equal(creneau.Add(intervalleNominatim)) {
+			prochaineRequete = creneau
+		}
+		limiteMutex.Unlock()
+		return ctx.Err()
+	}
+}
+
 // reponseNominatim - la structure de la reponse de l'API Nominatim
 type reponseNominatim struct {
 	Lat string `json:"lat"`
@@ -49,8 +88,9 @@ func NettoyerLieu(lieu string) string {
 }
 
 // Geocoder - convertit une adresse en coordonnees GPS
-// utilise le cache si on a deja cherche cette adresse
-func Geocoder(adresse string) (models.Coordonnees, error) {
+// utilise le cache si on a deja cherche cette adresse, sinon attend son tour (rate limit)
+// et abandonne si ctx est annule
+func Geocoder(ctx context.Context, adresse string) (models.Coordonnees, error) {
 	// on regarde d'abord dans le cache
 	cacheMutex.RLock()
 	if coords, ok := cacheCoords[adresse]; ok {
@@ -61,12 +101,15 @@ func Geocoder(adresse string) (models.Coordonnees, error) {
 
 	// pas dans le cache, on fait la requete a Nominatim
 	// faut respecter leur rate limit (1 requete par seconde)
+	if err := attendreTour(ctx); err != nil {
+		return models.Coordonnees{}, err
+	}
 	reqURL := fmt.Sprintf(
 		"https://nominatim.openstreetmap.org/search?format=json&q=%s&limit=1",
 		url.QueryEscape(adresse),
 	)
 
-	req, err := http.NewRequest("GET", reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return models.Coordonnees{}, fmt.Errorf("erreur creation requete: %w", err)
 	}
@@ -111,7 +154,7 @@ func Geocoder(adresse string) (models.Coordonnees, error) {
 
 // GeocoderLieuAPI - prend un lieu de l'API et le geocode
 // c'est un raccourci qui nettoie le lieu avant de le geocoder
-func GeocoderLieuAPI(lieuAPI string) (models.Coordonnees, error) {
+func GeocoderLieuAPI(ctx context.Context, lieuAPI string) (models.Coordonnees, error) {
 	adressePropre := NettoyerLieu(lieuAPI)
-	return Geocoder(adressePropre)
+	return Geocoder(ctx, adressePropre)
 }
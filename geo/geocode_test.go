@@ -0,0 +1,112 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"groupie-tracker/models"
+)
+
+// geocode_test.go - le rate limit de Nominatim partage par toute l'app
+// (pas de vraie requete ici: soit le lieu est en cache, soit le contexte est annule avant)
+
+func TestAttendreTourEspaceLesRequetes(t *testing.T) {
+	prochaineRequete = time.Time{}
+	debut := time.Now()
+
+	// trois pages qui geocodent en meme temps passent une par une
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() {
+			if err := attendreTour(context.Background()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if ecoule := time.Since(debut); ecoule < 2*intervalleNominatim {
+		t.Errorf("3 requetes en %v, attendu au moins %v", ecoule, 2*intervalleNominatim)
+	}
+}
+
+func TestAttendreTourAnnule(t *testing.T) {
+	prochaineRequete = time.Now().Add(time.Hour)
+
+	ctx, annuler := context.WithCancel(context.Background())
+	annuler()
+	if err := attendreTour(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, attendu context.Canceled", err)
+	}
+}
+
+func TestGeocoderCacheSansAttente(t *testing.T) {
+	prochaineRequete = time.Now().Add(time.Hour)
+	cacheMutex.Lock()
+	cacheCoords["london, uk"] = models.Coordonnees{Lat: 51.5, Lng: -0.12}
+	cacheMutex.Unlock()
+
+	// en cache: pas de tour a attendre, meme avec un contexte annule
+	ctx, annuler := context.WithCancel(context.Background())
+	annuler()
+	if c, err := GeocoderLieuAPI(ctx, "london-uk"); err != nil || c.Lat != 51.5 {
+		t.Errorf("london-uk = %+v, %v", c, err)
+	}
+
+	// pas en cache: le contexte annule arrete tout avant la requete
+	if _, err := GeocoderLieuAPI(ctx, "osaka-japan"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, attendu context.Canceled", err)
+	}
+}
+
+// attendreReservation - attend qu'une goroutine ait reserve le creneau qui suit apres
+func attendreReservation(apres time.Time) {
+	for {
+		limiteMutex.Lock()
+		reserve := prochaineRequete.After(apres)
+		limiteMutex.Unlock()
+		if reserve {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAttendreTourRendLeCreneau(t *testing.T) {
+	debut := time.Now().Add(time.Hour)
+	prochaineRequete = debut
+
+	// une page quittee pendant qu'elle attend rend son creneau
+	ctx, annuler := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer annuler()
+	if err := attendreTour(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, attendu context.DeadlineExceeded", err)
+	}
+	if !prochaineRequete.Equal(debut) {
+		t.Errorf("prochaine requete repoussee de %v par une attente annulee", prochaineRequete.Sub(debut))
+	}
+
+	// mais pas si quelqu'un a reserve apres: son creneau a lui bouge pas
+	premier, annulerPremier := context.WithCancel(context.Background())
+	fini := make(chan error)
+	go func() { fini <- attendreTour(premier) }()
+	attendreReservation(debut)
+	deuxieme, annulerDeuxieme := context.WithCancel(context.Background())
+	go func() { fini <- attendreTour(deuxieme) }()
+	attendreReservation(debut.Add(intervalleNominatim))
+
+	annulerPremier()
+	<-fini
+	if attendu := debut.Add(2 * intervalleNominatim); !prochaineRequete.Equal(attendu) {
+		t.Errorf("prochaine requete = +%v, attendu +%v", prochaineRequete.Sub(debut), attendu.Sub(debut))
+	}
+	annulerDeuxieme()
+	<-fini
+	// le deuxieme etait le dernier, il rend le sien
+	if attendu := debut.Add(intervalleNominatim); !prochaineRequete.Equal(attendu) {
+		t.Errorf("prochaine requete = +%v, attendu +%v", prochaineRequete.Sub(debut), attendu.Sub(debut))
+	}
+}
@@ -0,0 +1,218 @@
+package geo
+
+import (
+	"sort"
+	"strings"
+
+	"groupie-tracker/models"
+	"groupie-tracker/texte"
+)
+
+// hierarchie.go - range les lieux de concert en arbre continent -> pays -> region -> ville
+// l'API nous donne juste des trucs genre "seattle-usa" donc le continent et la region
+// on les deduit avec des petites tables faites main
+
+// les niveaux possibles d'un noeud dans l'arbre
+const (
+	NiveauContinent = "continent"
+	NiveauPays      = "pays"
+	NiveauRegion    = "region"
+	NiveauVille     = "ville"
+)
+
+// NoeudLieu - un noeud de l'arbre des lieux
+// l'ID c'est le chemin complet genre "europe/uk/london-uk", comme ca il est unique
+type NoeudLieu struct {
+	ID      string
+	Nom     string   // le nom lisible en minuscules genre "new zealand"
+	Niveau  string   // continent, pays, region ou ville
+	Cle     string   // la cle canonique du lieu (que pour les villes)
+	Enfants []string // les IDs des enfants, tries par nom
+}
+
+// HierarchieLieux - l'arbre complet des lieux, avec un acces direct par ID
+type HierarchieLieux struct {
+	Noeuds  map[string]*NoeudLieu
+	Racines []string // les IDs des continents
+}
+
+// continents - le continent de chaque pays qu'on trouve dans l'API
+var continents = map[string]string{
+	"usa": "north america", "canada": "north america", "mexico": "north america",
+	"costa_rica": "north america",
+
+	"brazil": "south america", "argentina": "south america", "chile": "south america",
+	"colombia": "south america", "peru": "south america", "venezuela": "south america",
+
+	"uk": "europe", "ireland": "europe", "france": "europe", "germany": "europe",
+	"spain": "europe", "portugal": "europe", "italy": "europe", "netherlands": "europe",
+	"belgium": "europe", "switzerland": "europe", "austria": "europe", "sweden": "europe",
+	"norway": "europe", "denmark": "europe", "finland": "europe", "poland": "europe",
+	"czech_republic": "europe", "czechia": "europe", "slovakia": "europe", "hungary": "europe",
+	"romania": "europe", "greece": "europe", "belarus": "europe", "russia": "europe",
+
+	"japan": "asia", "china": "asia", "south_korea": "asia", "korea": "asia",
+	"taiwan": "asia", "thailand": "asia", "india": "asia", "indonesia": "asia",
+	"philippines": "asia", "qatar": "asia", "united_arab_emirates": "asia",
+	"saudi_arabia": "asia",
+
+	"south_africa": "africa", "morocco": "africa", "egypt": "africa",
+
+	"australia": "oceania", "new_zealand": "oceania", "new_caledonia": "oceania",
+	"french_polynesia": "oceania",
+}
+
+// regions - la region (etat, province...) des villes qu'on connait
+// si une ville est pas dedans elle est rangee direct sous son pays
+var regions = map[string]string{
+	"seattle-usa":           "washington",
+	"los_angeles-usa":       "california",
+	"san_francisco-usa":     "california",
+	"anaheim-usa":           "california",
+	"las_vegas-usa":         "nevada",
+	"new_york-usa":          "new york",
+	"chicago-usa":           "illinois",
+	"houston-usa":           "texas",
+	"dallas-usa":            "texas",
+	"austin-usa":            "texas",
+	"atlanta-usa":           "georgia",
+	"boston-usa":            "massachusetts",
+	"philadelphia-usa":      "pennsylvania",
+	"detroit-usa":           "michigan",
+	"miami-usa":             "florida",
+	"orlando-usa":           "florida",
+	"denver-usa":            "colorado",
+	"phoenix-usa":           "arizona",
+	"nashville-usa":         "tennessee",
+	"new_orleans-usa":       "louisiana",
+	"minneapolis-usa":       "minnesota",
+	"toronto-canada":        "ontario",
+	"montreal-canada":       "quebec",
+	"vancouver-canada":      "british columbia",
+	"london-uk":             "england",
+	"manchester-uk":         "england",
+	"birmingham-uk":         "england",
+	"liverpool-uk":          "england",
+	"glasgow-uk":            "scotland",
+	"edinburgh-uk":          "scotland",
+	"cardiff-uk":            "wales",
+	"belfast-uk":            "northern ireland",
+	"sydney-australia":      "new south wales",
+	"melbourne-australia":   "victoria",
+	"brisbane-australia":    "queensland",
+	"perth-australia":       "western australia",
+	"munich-germany":        "bavaria",
+	"nuremberg-germany":     "bavaria",
+	"berlin-germany":        "berlin",
+	"frankfurt-germany":     "hesse",
+	"dusseldorf-germany":    "north rhine-westphalia",
+	"cologne-germany":       "north rhine-westphalia",
+	"saitama-japan":         "kanto",
+	"tokyo-japan":           "kanto",
+	"osaka-japan":           "kansai",
+	"nagoya-japan":          "chubu",
+	"sao_paulo-brazil":      "sao paulo",
+	"rio_de_janeiro-brazil": "rio de janeiro",
+}
+
+// CleLieu - donne la cle canonique d'un lieu de l'API, c'est elle qu'on compare
+// pour les filtres (plus de strings.Contains qui matche n'importe quoi)
+// sans accents ni majuscules, donc "são_paulo-brazil" et "Sao_Paulo-Brazil" donnent la meme cle
+func CleLieu(lieu string) string {
+	return texte.Normaliser(lieu)
+}
+
+// DecouperLieu - separe un lieu de l'API en ville et pays (bruts, avec les underscores)
+// "north_carolina-usa" -> "north_carolina", "usa"
+func DecouperLieu(lieu string) (ville, pays string) {
+	cle := CleLieu(lieu)
+	idx := strings.LastIndex(cle, "-")
+	if idx == -1 {
+		return cle, ""
+	}
+	return cle[:idx], cle[idx+1:]
+}
+
+// ContinentDuPays - retourne le continent d'un pays brut de l'API ("new_zealand")
+func ContinentDuPays(pays string) string {
+	if c, ok := continents[pays]; ok {
+		return c
+	}
+	return "other"
+}
+
+// ConstruireHierarchie - construit l'arbre de tous les lieux a partir de l'index de l'API
+func ConstruireHierarchie(locData models.IndexLocations) *HierarchieLieux {
+	h := &HierarchieLieux{Noeuds: make(map[string]*NoeudLieu)}
+
+	// petite fonction pour recuperer ou creer un noeud et l'accrocher a son parent
+	ajouter := func(parent, id, nom, niveau string) *NoeudLieu {
+		if n, ok := h.Noeuds[id]; ok {
+			return n
+		}
+		n := &NoeudLieu{ID: id, Nom: nom, Niveau: niveau}
+		h.Noeuds[id] = n
+		if parent == "" {
+			h.Racines = append(h.Racines, id)
+		} else {
+			h.Noeuds[parent].Enfants = append(h.Noeuds[parent].Enfants, id)
+		}
+		return n
+	}
+
+	for _, loc := range locData.Index {
+		for _, lieu := range loc.Locations {
+			cle := CleLieu(lieu)
+			ville, pays := DecouperLieu(cle)
+			if pays == "" {
+				continue
+			}
+
+			continent := ContinentDuPays(pays)
+			idContinent := continent
+			ajouter("", idContinent, continent, NiveauContinent)
+
+			idPays := idContinent + "/" + pays
+			ajouter(idContinent, idPays, strings.ReplaceAll(pays, "_", " "), NiveauPays)
+
+			parentVille := idPays
+			if region, ok := regions[cle]; ok {
+				idRegion := idPays + "/" + region
+				ajouter(idPays, idRegion, region, NiveauRegion)
+				parentVille = idRegion
+			}
+
+			n := ajouter(parentVille, parentVille+"/"+cle, strings.ReplaceAll(ville, "_", " "), NiveauVille)
+			n.Cle = cle
+		}
+	}
+
+	// on trie tout par nom pour que l'arbre soit lisible
+	parNom := func(ids []string) {
+		sort.Slice(ids, func(i, j int) bool {
+			return h.Noeuds[ids[i]].Nom < h.Noeuds[ids[j]].Nom
+		})
+	}
+	parNom(h.Racines)
+	for _, n := range h.Noeuds {
+		parNom(n.Enfants)
+	}
+
+	return h
+}
+
+// Villes - retourne les cles canoniques de toutes les villes sous un noeud (lui compris)
+func (h *HierarchieLieux) Villes(id string) []string {
+	n, ok := h.Noeuds[id]
+	if !ok {
+		return nil
+	}
+	if n.Niveau == NiveauVille {
+		return []string{n.Cle}
+	}
+	var cles []string
+	for _, enfant := range n.Enfants {
+		cles = append(cles, h.Villes(enfant)...)
+	}
+	return cles
+}
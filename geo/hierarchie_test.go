@@ -0,0 +1,44 @@
+package geo
+
+import "testing"
+
+// hierarchie_test.go - les cles de lieux: peu importe les accents et la casse de l'API,
+// le meme lieu doit donner la meme cle (c'est elle que les filtres et les pages comparent)
+
+func TestCleLieu(t *testing.T) {
+	cas := []struct {
+		lieu, attendu string
+	}{
+		{"sao_paulo-brazil", "sao_paulo-brazil"},
+		{"São_Paulo-Brazil", "sao_paulo-brazil"},
+		{"łódź-poland", "lodz-poland"},
+		{"Łódź-Poland", "lodz-poland"},
+		{"ZÜRICH-switzerland", "zurich-switzerland"},
+		{"düsseldorf-germany", "dusseldorf-germany"},
+		{"krakow-poland", "krakow-poland"},
+	}
+	for _, c := range cas {
+		if got := CleLieu(c.lieu); got != c.attendu {
+			t.Errorf("CleLieu(%q) = %q, attendu %q", c.lieu, got, c.attendu)
+		}
+	}
+}
+
+func TestDecouperLieu(t *testing.T) {
+	cas := []struct {
+		lieu, ville, pays string
+	}{
+		{"São_Paulo-Brazil", "sao_paulo", "brazil"},
+		{"north_carolina-usa", "north_carolina", "usa"},
+		{"Łódź-Poland", "lodz", "poland"},
+		{"dunedin-new_zealand", "dunedin", "new_zealand"},
+		{"sans-pays-france", "sans-pays", "france"},
+		{"nulle_part", "nulle_part", ""},
+	}
+	for _, c := range cas {
+		ville, pays := DecouperLieu(c.lieu)
+		if ville != c.ville || pays != c.pays {
+			t.Errorf("DecouperLieu(%q) = %q, %q, attendu %q, %q", c.lieu, ville, pays, c.ville, c.pays)
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"groupie-tracker/api"
+	"groupie-tracker/images"
+	"groupie-tracker/index"
 	"groupie-tracker/models"
+	"groupie-tracker/recherche"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -25,19 +30,56 @@ type AppGroupie struct {
 	fenetre          fyne.Window
 	artistes         []models.Artiste
 	locationsData    models.IndexLocations
-	contenuPrinc     *fyne.Container // le container principal ou on met les pages
+	relationsData    models.IndexRelations
+	fiches           map[int]recherche.Fiche // artiste + lieux + concerts, pour la recherche avancee
+	index            *index.Index            // l'index de recherche, construit une fois au chargement
+	membres          *index.IndexMembres     // les membres avec tous leurs groupes
+	bornes           BornesFiltres           // les min/max des filtres, calcules une fois au chargement
+	contenuPrinc     *fyne.Container         // le container principal ou on met les pages
 	pageAccueil      fyne.CanvasObject
-	cacheImages      map[int][]byte // cache des images telecharges
-	cacheImagesMu    sync.RWMutex
-	favoris          map[int]bool // les favoris de l'utilisateur
+	images           *images.Cache // les images des artistes (memoire + disque)
+	favoris          map[int]bool  // les favoris de l'utilisateur
 	favorisMu        sync.RWMutex
-	barreRecherche   *EntryRecherche // la barre de recherche (pour les raccourcis)
-	onRefreshAccueil func()          // callback pour rafraichir la page d'accueil
+	collections      []Collection        // les collections nommees de l'utilisateur
+	tags             map[int][]string    // les tags libres de chaque artiste
+	classementMu     sync.RWMutex        // protege collections et tags
+	notes            map[int]NoteArtiste // les notes et etoiles de l'utilisateur
+	notesMu          sync.RWMutex
+	barreRecherche   *EntryRecherche    // la barre de recherche (pour les raccourcis)
+	onRefreshAccueil func()             // callback pour rafraichir la page d'accueil
+	tacheRecherche   *tacheAnnulable    // le calcul de recherche en arriere plan de l'accueil
+	annulerAccueil   func()             // abandonne les images en cours de l'accueil quand on le quitte
+	annulerPage      func()             // abandonne les chargements de la page principale (carte, images) quand on la quitte
+	navigation       navigation         // l'historique des pages pour precedent / suivant
+	capturerPage     func(*EtatPage)    // la page affichee note ou on en est (recherche, scroll...), peut etre nil
+	pagePrincipale   fyne.CanvasObject  // la page du routeur, seule dans la fenetre ou dans le premier onglet
+	onglets          *container.DocTabs // nil tant qu'aucun artiste est ouvert dans un onglet
+	ongletPrincipal  *container.TabItem
+	ongletsArtistes  []*ongletArtiste // les artistes ouverts dans des onglets, dans l'ordre
+	comparaison      []int            // les artistes choisis pour la comparaison (2 a 4)
+	btnComparaison   *widget.Button   // le bouton "Comparer" de l'accueil, nil avant le premier accueil
 }
 
+// maxMemoireImages - la memoire max pour les images (entieres + miniatures), le reste est sur le disque
+const maxMemoireImages = 64 << 20
+
+// maxDisqueImages - la place max du cache des images sur le disque, les plus vieilles partent au dela
+const maxDisqueImages = 256 << 20
+
+// le prechargement des images au lancement, reglable dans les preferences Fyne
+// (workersImages: le nb de workers, maxTelechargements: la limite globale pour toute l'app)
+const (
+	clePrefWorkersImages       = "images_workers"
+	clePrefMaxTelechargements  = "images_max_telechargements"
+	workersImagesDefaut        = 8
+	maxTelechargementsDefaut   = 6
+	attentePrechargementImages = 5 * time.Second // au dela on affiche l'accueil, le reste continue derriere
+)
+
 // LancerApp - point d'entrée de l'interface graphique
 // on charge les données et on lance la fenetre
-func LancerApp() {
+// args c'est la ligne de commande: un lien groupie:// ou une recherche a ouvrir direct
+func LancerApp(args []string) {
 	// on cree l'app Fyne
 	monApp := app.NewWithID("com.groupie.tracker")
 	monApp.Settings().SetTheme(theme.DarkTheme())
@@ -56,37 +98,96 @@ func LancerApp() {
 	go func() {
 		artistes, err := api.RecupererArtistes()
 		if err != nil {
-			labelChargement.SetText(fmt.Sprintf("❌ Erreur: %v", err))
+			fyne.Do(func() {
+				labelChargement.SetText(fmt.Sprintf("❌ Erreur: %v", err))
+			})
 			return
 		}
 
+		// les images se prechargent pendant qu'on recupere le reste
+		prefs := monApp.Preferences()
+		cacheImages := images.NouveauCache(images.DossierParDefaut(), maxMemoireImages, maxDisqueImages,
+			prefs.IntWithFallback(clePrefMaxTelechargements, maxTelechargementsDefaut), api.RecupererImageArtisteCtx)
+		imagesPretes := make(chan struct{})
+		go func() {
+			defer close(imagesPretes)
+			urls := make([]string, len(artistes))
+			for i, art := range artistes {
+				urls[i] = art.Image
+			}
+			erreurs := cacheImages.Precharger(context.Background(), urls, prefs.IntWithFallback(clePrefWorkersImages, workersImagesDefaut),
+				func(faites, total int) {
+					fyne.Do(func() {
+						labelChargement.SetText(fmt.Sprintf("⏳ Chargement des artistes... 🖼 %d/%d images", faites, total))
+					})
+				})
+			if erreurs > 0 {
+				fmt.Println("Warning:", erreurs, "images pas prechargees")
+			}
+		}()
+
 		locData, err := api.RecupererToutesLocations()
 		if err != nil {
 			// c'est pas grave si on a pas les locations, on continue quand meme
 			fmt.Println("Warning: impossible de charger les locations:", err)
 		}
 
+		relData, err := api.RecupererToutesRelations()
+		if err != nil {
+			// pareil, sans les relations on perd juste les criteres sur les concerts
+			fmt.Println("Warning: impossible de charger les relations:", err)
+		}
+
 		appGrp := &AppGroupie{
 			app:           monApp,
 			fenetre:       fenetre,
 			artistes:      artistes,
 			locationsData: locData,
-			cacheImages:   make(map[int][]byte),
-			favoris:       make(map[int]bool),
+			relationsData: relData,
+			bornes:        calculerBornes(artistes),
+			images:        cacheImages,
 		}
 
-		// on setup les raccourcis clavier
-		appGrp.setupRaccourcis()
+		appGrp.chargerFavoris()
+		appGrp.chargerCollections()
+		appGrp.chargerNotes()
+		appGrp.construireFiches()
+		appGrp.index = index.Construire(artistes, locData, relData)
+		appGrp.membres = index.ConstruireMembres(artistes)
+
+		// on laisse un peu de temps aux images pour que la grille arrive pas toute vide
+		select {
+		case <-imagesPretes:
+		case <-time.After(attentePrechargementImages):
+		}
+
+		// on ouvre la page demandee en argument, sinon l'accueil
+		etat, ok, err := appGrp.parserArguments(args)
+		if err != nil {
+			fmt.Println("Warning: lien pas compris, on ouvre l'accueil:", err)
+		}
+		if !ok {
+			etat = EtatPage{Page: pageAccueil}
+		}
+
+		// tout ce qui touche a la fenetre se fait dans le thread de l'UI
+		fyne.Do(func() {
+			// on setup les raccourcis clavier
+			appGrp.setupRaccourcis()
+
+			appGrp.naviguer(etat)
 
-		// on cree la page d'accueil et on l'affiche
-		appGrp.afficherAccueil()
+			// et on rouvre les onglets de la derniere fois, derriere la page principale
+			appGrp.restaurerOnglets()
+		})
 	}()
 
 	monApp.Run()
 }
 
 // setupRaccourcis - configure les raccourcis clavier globaux
-// Ctrl+F -> focus sur la recherche, Escape -> retour accueil
+// Ctrl+F -> focus sur la recherche, Ctrl+H -> retour accueil, Alt+Gauche / Alt+Droite -> precedent / suivant
+// Ctrl+L -> copie le lien de la page, Ctrl+W -> ferme l'onglet affiche
 func (a *AppGroupie) setupRaccourcis() {
 	// Ctrl+F pour la recherche
 	ctrlF := &desktop.CustomShortcut{
@@ -108,66 +209,148 @@ func (a *AppGroupie) setupRaccourcis() {
 	a.fenetre.Canvas().AddShortcut(ctrlH, func(shortcut fyne.Shortcut) {
 		a.afficherAccueil()
 	})
+
+	// Alt+Gauche / Alt+Droite pour naviguer dans l'historique, comme dans un navigateur
+	altGauche := &desktop.CustomShortcut{
+		KeyName:  fyne.KeyLeft,
+		Modifier: fyne.KeyModifierAlt,
+	}
+	a.fenetre.Canvas().AddShortcut(altGauche, func(shortcut fyne.Shortcut) {
+		a.pagePrecedente()
+	})
+	altDroite := &desktop.CustomShortcut{
+		KeyName:  fyne.KeyRight,
+		Modifier: fyne.KeyModifierAlt,
+	}
+	a.fenetre.Canvas().AddShortcut(altDroite, func(shortcut fyne.Shortcut) {
+		a.pageSuivante()
+	})
+
+	// Ctrl+L pour copier le lien groupie:// de la page
+	ctrlL := &desktop.CustomShortcut{
+		KeyName:  fyne.KeyL,
+		Modifier: fyne.KeyModifierControl,
+	}
+	a.fenetre.Canvas().AddShortcut(ctrlL, func(shortcut fyne.Shortcut) {
+		a.copierLienPage()
+	})
+
+	// Ctrl+W pour fermer l'onglet d'artiste affiche
+	ctrlW := &desktop.CustomShortcut{
+		KeyName:  fyne.KeyW,
+		Modifier: fyne.KeyModifierControl,
+	}
+	a.fenetre.Canvas().AddShortcut(ctrlW, func(shortcut fyne.Shortcut) {
+		a.fermerOngletCourant()
+	})
 }
 
-// afficherAccueil - affiche la page d'accueil avec la grille d'artistes
+// afficherAccueil - affiche la page d'accueil avec la grille d'artistes (toute neuve)
 func (a *AppGroupie) afficherAccueil() {
-	page := a.creerPageAccueil()
-	a.fenetre.SetContent(page)
+	a.naviguer(EtatPage{Page: pageAccueil})
 }
 
 // afficherDetail - affiche la page de detail d'un artiste
 func (a *AppGroupie) afficherDetail(artiste models.Artiste) {
-	page := a.creerPageDetail(artiste)
-	a.fenetre.SetContent(page)
+	a.naviguer(EtatPage{Page: pageArtiste, ArtisteID: artiste.ID})
 }
 
-// getImageArtiste - recupere l'image d'un artiste depuis le cache ou l'API
-func (a *AppGroupie) getImageArtiste(artiste models.Artiste) []byte {
-	a.cacheImagesMu.RLock()
-	if data, ok := a.cacheImages[artiste.ID]; ok {
-		a.cacheImagesMu.RUnlock()
-		return data
+// afficherMembre - affiche la page d'un membre (par son nom, peu importe l'orthographe)
+func (a *AppGroupie) afficherMembre(nom string) {
+	if membre, ok := a.membres.Membre(nom); ok {
+		a.naviguer(EtatPage{Page: pageMembre, Cle: membre.Nom})
 	}
-	a.cacheImagesMu.RUnlock()
+}
 
-	data, err := api.RecupererImageArtiste(artiste.Image)
-	if err != nil {
-		fmt.Println("Erreur image pour", artiste.Nom, ":", err)
-		return nil
+// afficherLieu - affiche la page d'un lieu (le lieu tel que l'API le donne, genre "london-uk")
+func (a *AppGroupie) afficherLieu(lieu string) {
+	a.naviguer(EtatPage{Page: pageLieu, Cle: lieu})
+}
+
+// quitterAccueil - avant d'aller sur une autre page: on arrete la recherche en cours,
+// on ferme les suggestions et on garde la recherche qui a mene la dans l'historique
+func (a *AppGroupie) quitterAccueil() {
+	if a.tacheRecherche != nil {
+		a.tacheRecherche.Annuler()
+	}
+	if a.annulerAccueil != nil {
+		a.annulerAccueil()
+	}
+	a.onRefreshAccueil = nil // la grille d'accueil n'est plus a l'ecran
+	if a.barreRecherche != nil {
+		a.barreRecherche.CacherSuggestions()
+		a.ajouterRecherche(a.barreRecherche.Text)
 	}
+}
 
-	a.cacheImagesMu.Lock()
-	a.cacheImages[artiste.ID] = data
-	a.cacheImagesMu.Unlock()
+// ficheArtiste - la fiche de recherche d'un artiste avec ses collections, ses tags et sa note du moment
+// (ils changent pendant que l'app tourne, on les met pas dans a.fiches)
+func (a *AppGroupie) ficheArtiste(id int) recherche.Fiche {
+	f := a.fiches[id]
+	f.Collections, f.Tags = a.etiquettesArtiste(id)
+	note := a.noteArtiste(id)
+	f.Note, f.Etoiles = note.Texte, note.Etoiles
+	return f
+}
 
-	return data
+// artisteParID - retrouve un artiste par son ID
+func (a *AppGroupie) artisteParID(id int) (models.Artiste, bool) {
+	for _, art := range a.artistes {
+		if art.ID == id {
+			return art, true
+		}
+	}
+	return models.Artiste{}, false
+}
+
+// construireFiches - regroupe pour chaque artiste ses lieux et ses concerts
+// on le fait une fois au chargement, la recherche avancee s'en sert a chaque frappe
+func (a *AppGroupie) construireFiches() {
+	a.fiches = make(map[int]recherche.Fiche, len(a.artistes))
+	for _, art := range a.artistes {
+		a.fiches[art.ID] = recherche.Fiche{Artiste: art}
+	}
+	for _, loc := range a.locationsData.Index {
+		if f, ok := a.fiches[loc.ID]; ok {
+			f.Lieux = loc.Locations
+			a.fiches[loc.ID] = f
+		}
+	}
+	for _, rel := range a.relationsData.Index {
+		if f, ok := a.fiches[rel.ID]; ok {
+			f.Concerts = rel.DatesLocations
+			a.fiches[rel.ID] = f
+		}
+	}
+}
+
+// artistesPourTexte - le texte libre de la recherche, resolu par l'index
+// la grille prend tous les types d'entree, comme ca elle montre exactement les artistes
+// qui ont une suggestion (avant "seattle" donnait une suggestion mais une grille vide)
+func (a *AppGroupie) artistesPourTexte(texte string) map[int]bool {
+	return a.index.ArtistesPour(texte)
 }
 
-// toggleFavori - ajoute ou enleve un artiste des favoris
-func (a *AppGroupie) toggleFavori(id int) {
-	a.favorisMu.Lock()
-	if a.favoris[id] {
-		delete(a.favoris, id)
-	} else {
-		a.favoris[id] = true
+// getImageArtiste - recupere l'image d'un artiste depuis le cache ou l'API
+func (a *AppGroupie) getImageArtiste(artiste models.Artiste) []byte {
+	data, err := a.chargerImageArtiste(context.Background(), artiste)
+	if err != nil {
+		fmt.Println("Erreur image pour", artiste.Nom, ":", err)
+		return nil
 	}
-	a.favorisMu.Unlock()
+	return data
 }
 
-// estFavori - verifie si un artiste est dans les favoris
-func (a *AppGroupie) estFavori(id int) bool {
-	a.favorisMu.RLock()
-	defer a.favorisMu.RUnlock()
-	return a.favoris[id]
+// chargerImageArtiste - pareil que getImageArtiste mais annulable, et on renvoie l'erreur
+// (un telechargement annule c'est pas une vraie erreur, l'appelant decide quoi afficher)
+func (a *AppGroupie) chargerImageArtiste(ctx context.Context, artiste models.Artiste) ([]byte, error) {
+	return a.images.Image(ctx, artiste.Image)
 }
 
-// creerBoutonRetour - cree un bouton retour vers la page d'accueil
-func (a *AppGroupie) creerBoutonRetour() *widget.Button {
-	btn := widget.NewButtonWithIcon("Retour", theme.NavigateBackIcon(), func() {
-		a.afficherAccueil()
-	})
-	return btn
+// chargerMiniatureArtiste - l'image reduite, pour les cards et tout ce qui est petit
+// (la page de detail garde l'image entiere)
+func (a *AppGroupie) chargerMiniatureArtiste(ctx context.Context, artiste models.Artiste) ([]byte, error) {
+	return a.images.Miniature(ctx, artiste.Image)
 }
 
 // creerHeader - cree le header commun avec le titre et les raccourcis
@@ -175,7 +358,7 @@ func creerHeader() *fyne.Container {
 	titre := widget.NewLabel("🎵 Groupie Tracker")
 	titre.TextStyle = fyne.TextStyle{Bold: true}
 
-	raccourcisInfo := widget.NewLabel("Ctrl+F: Recherche | Ctrl+H: Accueil")
+	raccourcisInfo := widget.NewLabel("Ctrl+F: Recherche | Ctrl+H: Accueil | Alt+←/→: Précédent/Suivant | Ctrl+L: Lien")
 	raccourcisInfo.TextStyle = fyne.TextStyle{Italic: true}
 
 	return container.NewHBox(titre, layout.NewSpacer(), raccourcisInfo)
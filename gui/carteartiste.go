@@ -0,0 +1,234 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"groupie-tracker/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// carteartiste.go - la card d'un artiste dans la grille
+// la grille de l'accueil est virtualisee: elle cree juste assez de cards pour remplir l'ecran
+// et les reutilise en scrollant, donc une card peut changer d'artiste n'importe quand
+// l'image se charge en arriere plan (avec une image d'attente), et si la card change
+// d'artiste avant la fin on abandonne le telechargement
+
+// tailleCarte - la taille d'une card dans la grille
+var tailleCarte = fyne.NewSize(220, 410)
+
+// attenteImage - on attend un peu avant de telecharger, comme ca quand on scrolle vite
+// les cards qui font que passer ne lancent rien
+const attenteImage = 100 * time.Millisecond
+
+// maxChipsCarte - le nb max de chips (collections + tags) sur une card
+const maxChipsCarte = 3
+
+// carteArtiste - une card reutilisable
+type carteArtiste struct {
+	widget.BaseWidget
+	app *AppGroupie
+
+	artiste models.Artiste
+	ctx     context.Context // le contexte de la page, annule quand on la quitte
+	annuler context.CancelFunc
+
+	image       *canvas.Image
+	labelNom    *widget.Label
+	labelAnnee  *widget.Label
+	zoneChips   *fyne.Container
+	zoneRaison  *fyne.Container
+	btnFavori   *widget.Button
+	btnComparer *widget.Button
+	contenu     fyne.CanvasObject
+}
+
+// newCarteArtiste - une card vide, a remplir avec afficher
+// ctx c'est celui de la page: quand il est annule, les images en cours sont abandonnees
+func (a *AppGroupie) newCarteArtiste(ctx context.Context) *carteArtiste {
+	c := &carteArtiste{app: a, ctx: ctx}
+
+	c.image = canvas.NewImageFromResource(theme.MediaPhotoIcon())
+	c.image.FillMode = canvas.ImageFillContain
+	c.image.SetMinSize(fyne.NewSize(150, 150))
+
+	// le nom de l'artiste
+	c.labelNom = widget.NewLabel("")
+	c.labelNom.TextStyle = fyne.TextStyle{Bold: true}
+	c.labelNom.Alignment = fyne.TextAlignCenter
+	c.labelNom.Wrapping = fyne.TextWrapWord
+
+	// l'annee de creation
+	c.labelAnnee = widget.NewLabel("")
+	c.labelAnnee.Alignment = fyne.TextAlignCenter
+
+	// les collections et tags de l'artiste
+	c.zoneChips = container.NewHBox()
+
+	// pourquoi l'artiste matche la recherche (vide si y'a rien a dire)
+	c.zoneRaison = container.NewVBox()
+
+	// etoile favori
+	c.btnFavori = widget.NewButton("☆", func() {
+		a.toggleFavori(c.artiste.ID)
+		c.majFavori()
+		// la grille peut etre triee par favoris, on la recalcule
+		if a.onRefreshAccueil != nil {
+			a.onRefreshAccueil()
+		}
+	})
+	c.btnFavori.Importance = widget.LowImportance
+
+	// choisir l'artiste pour la comparaison
+	c.btnComparer = widget.NewButton("⚖", func() {
+		a.basculerComparaison(c.artiste.ID)
+		c.majComparaison()
+	})
+
+	// bouton pour voir le detail
+	btnDetail := widget.NewButton("Voir détails", func() {
+		a.ouvrirArtiste(c.artiste)
+	})
+	btnDetail.Importance = widget.MediumImportance
+
+	// on assemble le tout dans un container vertical
+	cardContent := container.NewVBox(
+		c.image,
+		c.labelNom,
+		c.labelAnnee,
+		c.zoneChips,
+		c.zoneRaison,
+		container.NewHBox(layout.NewSpacer(), c.btnFavori, c.btnComparer, layout.NewSpacer()),
+		btnDetail,
+	)
+
+	// le fond invisible donne la taille fixe de la grille, avec ou sans ligne de raison
+	fond := canvas.NewRectangle(nil)
+	fond.SetMinSize(tailleCarte)
+	c.contenu = container.NewStack(fond, widget.NewCard("", "", cardContent))
+
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *carteArtiste) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.contenu)
+}
+
+// MouseDown / MouseUp - le clic molette sur une card ouvre l'artiste dans un nouvel onglet
+func (c *carteArtiste) MouseDown(ev *desktop.MouseEvent) {}
+
+func (c *carteArtiste) MouseUp(ev *desktop.MouseEvent) {
+	if ev.Button == desktop.MouseButtonTertiary && c.artiste.ID != 0 {
+		c.app.ouvrirOnglet(c.artiste, true)
+	}
+}
+
+// afficher - met la card sur un artiste (raison peut etre nil)
+func (c *carteArtiste) afficher(artiste models.Artiste, raison *raisonCorrespondance) {
+	memeArtiste := c.artiste.ID == artiste.ID && c.annuler != nil
+	c.artiste = artiste
+
+	c.labelNom.SetText(artiste.Nom)
+	c.labelAnnee.SetText(fmt.Sprintf("📅 %d", artiste.DateCreation))
+	c.majFavori()
+	c.majComparaison()
+
+	// les chips centrees, 3 max sinon ca deborde de la card
+	chips := []fyne.CanvasObject{layout.NewSpacer()}
+	chips = append(chips, c.app.creerChipsArtiste(artiste.ID, maxChipsCarte)...)
+	c.zoneChips.Objects = append(chips, layout.NewSpacer())
+	c.zoneChips.Refresh()
+
+	c.zoneRaison.RemoveAll()
+	if raison != nil {
+		if ligne := creerLigneRaison(*raison); ligne != nil {
+			c.zoneRaison.Add(ligne)
+		}
+	}
+
+	if !memeArtiste {
+		c.chargerImage()
+	}
+}
+
+// majFavori - l'etoile pleine ou vide
+func (c *carteArtiste) majFavori() {
+	if c.app.estFavori(c.artiste.ID) {
+		c.btnFavori.SetText("⭐")
+	} else {
+		c.btnFavori.SetText("☆")
+	}
+}
+
+// majComparaison - le bouton ⚖ en avant si l'artiste est choisi pour la comparaison
+func (c *carteArtiste) majComparaison() {
+	if c.app.dansComparaison(c.artiste.ID) {
+		c.btnComparer.Importance = widget.HighImportance
+	} else {
+		c.btnComparer.Importance = widget.LowImportance
+	}
+	c.btnComparer.Refresh()
+}
+
+// chargerImage - abandonne l'image de l'ancien artiste et lance celle du nouveau
+func (c *carteArtiste) chargerImage() {
+	if c.annuler != nil {
+		c.annuler()
+	}
+	ctx, annuler := context.WithCancel(c.ctx)
+	c.annuler = annuler
+
+	artiste := c.artiste
+	c.image.Resource = theme.MediaPhotoIcon()
+	c.image.Refresh()
+
+	go func() {
+		// si l'image est deja en cache pas la peine d'attendre
+		if !c.app.images.MiniatureEnMemoire(artiste.Image) {
+			select {
+			case <-time.After(attenteImage):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		data, err := c.app.chargerMiniatureArtiste(ctx, artiste)
+		if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return // la card est passee a autre chose
+		}
+		if err != nil {
+			fmt.Println("Erreur image pour", artiste.Nom, ":", err)
+		}
+
+		fyne.Do(func() {
+			// on reverifie dans le thread de l'UI, la card a pu changer entre temps
+			if ctx.Err() != nil || c.artiste.ID != artiste.ID {
+				return
+			}
+			if err != nil || len(data) == 0 {
+				c.image.Resource = theme.BrokenImageIcon()
+			} else {
+				c.image.Resource = fyne.NewStaticResource(fmt.Sprintf("artist_%d", artiste.ID), data)
+			}
+			c.image.Refresh()
+		})
+	}()
+}
+
+// creerCardArtiste - une card toute seule pour un artiste, pour les pages hors de la grille
+// ctx c'est celui de la page: son image est abandonnee quand on la quitte
+func (a *AppGroupie) creerCardArtiste(ctx context.Context, artiste models.Artiste) fyne.CanvasObject {
+	c := a.newCarteArtiste(ctx)
+	c.afficher(artiste, nil)
+	return c
+}
@@ -0,0 +1,330 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// collections.go - les collections nommees ("Festival 2027 shortlist") et les tags libres
+// c'est le cran au dessus des favoris: un artiste peut etre dans plusieurs collections
+// et avoir autant de tags qu'on veut; tout est garde dans les preferences Fyne
+// on s'en sert dans les filtres, dans la recherche (tag: et collection:) et en chips sur les cards
+// une collection existe tant qu'elle a au moins un artiste, on la cree en y mettant le premier
+
+// les cles dans les preferences Fyne
+const (
+	clePrefCollections = "collections"
+	clePrefTags        = "tags"
+)
+
+// Collection - une liste nommee d'artistes
+type Collection struct {
+	Nom      string `json:"nom"`
+	Artistes []int  `json:"artistes"`
+}
+
+// chargerCollections - lit les collections et les tags sauves (au lancement)
+func (a *AppGroupie) chargerCollections() {
+	var collections []Collection
+	tags := make(map[int][]string)
+	chargerPrefJSON(a.app.Preferences(), clePrefCollections, &collections)
+	chargerPrefJSON(a.app.Preferences(), clePrefTags, &tags)
+
+	a.classementMu.Lock()
+	a.collections = collections
+	a.tags = tags
+	a.classementMu.Unlock()
+}
+
+// sauverCollections - ecrit les collections et les tags (a appeler sans le verrou)
+func (a *AppGroupie) sauverCollections() {
+	a.classementMu.RLock()
+	defer a.classementMu.RUnlock()
+	sauverPrefJSON(a.app.Preferences(), clePrefCollections, a.collections)
+	sauverPrefJSON(a.app.Preferences(), clePrefTags, a.tags)
+}
+
+// nomsCollections - toutes les collections, triees par nom
+func (a *AppGroupie) nomsCollections() []string {
+	a.classementMu.RLock()
+	noms := make([]string, len(a.collections))
+	for i, c := range a.collections {
+		noms[i] = c.Nom
+	}
+	a.classementMu.RUnlock()
+	sort.Slice(noms, func(i, j int) bool { return texte.Normaliser(noms[i]) < texte.Normaliser(noms[j]) })
+	return noms
+}
+
+// tousLesTags - tous les tags utilises au moins une fois, tries
+// deux tags qui s'ecrivent pareil aux accents / majuscules pres comptent pour un
+func (a *AppGroupie) tousLesTags() []string {
+	vus := make(map[string]string)
+	a.classementMu.RLock()
+	for _, tags := range a.tags {
+		for _, t := range tags {
+			if _, ok := vus[texte.Normaliser(t)]; !ok {
+				vus[texte.Normaliser(t)] = t
+			}
+		}
+	}
+	a.classementMu.RUnlock()
+
+	tous := make([]string, 0, len(vus))
+	for _, t := range vus {
+		tous = append(tous, t)
+	}
+	sort.Slice(tous, func(i, j int) bool { return texte.Normaliser(tous[i]) < texte.Normaliser(tous[j]) })
+	return tous
+}
+
+// etiquettesArtiste - les collections et les tags d'un artiste
+func (a *AppGroupie) etiquettesArtiste(id int) (collections, tags []string) {
+	a.classementMu.RLock()
+	defer a.classementMu.RUnlock()
+	for _, c := range a.collections {
+		for _, idArt := range c.Artistes {
+			if idArt == id {
+				collections = append(collections, c.Nom)
+				break
+			}
+		}
+	}
+	tags = append(tags, a.tags[id]...)
+	return collections, tags
+}
+
+// basculerCollection - met l'artiste dans la collection (creee au besoin) ou l'en retire
+// une collection qui se retrouve vide disparait
+func (a *AppGroupie) basculerCollection(nom string, id int) {
+	nom = strings.TrimSpace(nom)
+	if nom == "" {
+		return
+	}
+
+	a.classementMu.Lock()
+	trouvee := false
+	for i, c := range a.collections {
+		if texte.Normaliser(c.Nom) != texte.Normaliser(nom) {
+			continue
+		}
+		trouvee = true
+		dedans := -1
+		for j, idArt := range c.Artistes {
+			if idArt == id {
+				dedans = j
+			}
+		}
+		if dedans == -1 {
+			a.collections[i].Artistes = append(c.Artistes, id)
+		} else if len(c.Artistes) == 1 {
+			a.collections = append(a.collections[:i], a.collections[i+1:]...)
+		} else {
+			a.collections[i].Artistes = append(c.Artistes[:dedans], c.Artistes[dedans+1:]...)
+		}
+		break
+	}
+	if !trouvee {
+		a.collections = append(a.collections, Collection{Nom: nom, Artistes: []int{id}})
+	}
+	a.classementMu.Unlock()
+
+	a.sauverCollections()
+}
+
+// ajouterTag - ajoute un tag a l'artiste (rien si il l'a deja)
+func (a *AppGroupie) ajouterTag(id int, tag string) {
+	tag = strings.Join(strings.Fields(tag), " ")
+	if tag == "" {
+		return
+	}
+
+	a.classementMu.Lock()
+	for _, t := range a.tags[id] {
+		if texte.Normaliser(t) == texte.Normaliser(tag) {
+			a.classementMu.Unlock()
+			return
+		}
+	}
+	a.tags[id] = append(a.tags[id], tag)
+	a.classementMu.Unlock()
+
+	a.sauverCollections()
+}
+
+// enleverTag - enleve un tag de l'artiste
+func (a *AppGroupie) enleverTag(id int, tag string) {
+	a.classementMu.Lock()
+	var restants []string
+	for _, t := range a.tags[id] {
+		if texte.Normaliser(t) != texte.Normaliser(tag) {
+			restants = append(restants, t)
+		}
+	}
+	if len(restants) == 0 {
+		delete(a.tags, id)
+	} else {
+		a.tags[id] = restants
+	}
+	a.classementMu.Unlock()
+
+	a.sauverCollections()
+}
+
+// les couleurs des chips
+var (
+	couleurChipCollection = color.NRGBA{R: 0x3a, G: 0x5f, B: 0x9e, A: 0xff}
+	couleurChipTag        = color.NRGBA{R: 0x4a, G: 0x4a, B: 0x55, A: 0xff}
+)
+
+// creerChip - un petit badge arrondi avec du texte
+func creerChip(libelle string, fond color.Color) fyne.CanvasObject {
+	rect := canvas.NewRectangle(fond)
+	rect.CornerRadius = 8
+
+	txt := canvas.NewText(libelle, theme.Color(theme.ColorNameForeground))
+	txt.TextSize = theme.CaptionTextSize()
+
+	return container.NewStack(rect, container.New(&margeChip{}, txt))
+}
+
+// margeChip - un padding plus petit que celui de Fyne, sinon les chips sont enormes
+type margeChip struct{}
+
+const tailleMargeChip = 4
+
+func (m *margeChip) MinSize(objets []fyne.CanvasObject) fyne.Size {
+	taille := objets[0].MinSize()
+	return fyne.NewSize(taille.Width+3*tailleMargeChip, taille.Height+tailleMargeChip)
+}
+
+func (m *margeChip) Layout(objets []fyne.CanvasObject, taille fyne.Size) {
+	for _, o := range objets {
+		o.Move(fyne.NewPos(1.5*tailleMargeChip, tailleMargeChip/2))
+		o.Resize(o.MinSize())
+	}
+}
+
+// longueurMaxChip - sur les cards la place est comptee, les noms trop longs sont coupes
+const longueurMaxChip = 14
+
+// tronquer - coupe un texte a n caracteres (pas octets) avec des points de suspension
+func tronquer(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// creerChipsArtiste - les chips des collections puis des tags d'un artiste
+// maxi limite le nb de chips (0 = tout), le reste est resume en "+N" et les noms sont coupes
+func (a *AppGroupie) creerChipsArtiste(id int, maxi int) []fyne.CanvasObject {
+	collections, tags := a.etiquettesArtiste(id)
+	var chips []fyne.CanvasObject
+	total := len(collections) + len(tags)
+	for i, nom := range append(collections, tags...) {
+		if maxi > 0 && len(chips) == maxi-1 && total > maxi {
+			chips = append(chips, creerChip(fmt.Sprintf("+%d", total-len(chips)), couleurChipTag))
+			break
+		}
+		if maxi > 0 {
+			nom = tronquer(nom, longueurMaxChip)
+		}
+		if i < len(collections) {
+			chips = append(chips, creerChip("📁 "+nom, couleurChipCollection))
+		} else {
+			chips = append(chips, creerChip("#"+nom, couleurChipTag))
+		}
+	}
+	return chips
+}
+
+// creerSectionClassement - sur la page de detail: les collections (a cocher) et les tags (a enlever / ajouter)
+// onChange est appele apres chaque modif, la page se redessine avec
+func (a *AppGroupie) creerSectionClassement(id int, onChange func()) fyne.CanvasObject {
+	collections, tags := a.etiquettesArtiste(id)
+
+	// === COLLECTIONS ===
+	labelCollections := widget.NewLabel("📁 Collections:")
+	labelCollections.TextStyle = fyne.TextStyle{Bold: true}
+
+	dedans := make(map[string]bool, len(collections))
+	for _, c := range collections {
+		dedans[c] = true
+	}
+	checks := container.NewHBox()
+	for _, nom := range a.nomsCollections() {
+		nomCollection := nom // capture pour la closure
+		check := widget.NewCheck(nomCollection, func(bool) {
+			a.basculerCollection(nomCollection, id)
+			onChange()
+		})
+		check.Checked = dedans[nomCollection]
+		checks.Add(check)
+	}
+
+	entryCollection := widget.NewEntry()
+	entryCollection.SetPlaceHolder("Nouvelle collection...")
+	ajouterCollection := func() {
+		nom := strings.TrimSpace(entryCollection.Text)
+		if nom == "" {
+			return
+		}
+		for _, c := range collections {
+			if texte.Normaliser(c) == texte.Normaliser(nom) {
+				return // deja dedans, basculer l'enleverait
+			}
+		}
+		a.basculerCollection(nom, id)
+		onChange()
+	}
+	entryCollection.OnSubmitted = func(string) { ajouterCollection() }
+	btnCollection := widget.NewButtonWithIcon("", theme.ContentAddIcon(), ajouterCollection)
+
+	// === TAGS ===
+	labelTags := widget.NewLabel("🏷 Tags:")
+	labelTags.TextStyle = fyne.TextStyle{Bold: true}
+
+	chipsTags := container.NewHBox()
+	for _, t := range tags {
+		tag := t // capture pour la closure
+		btnEnlever := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+			a.enleverTag(id, tag)
+			onChange()
+		})
+		btnEnlever.Importance = widget.LowImportance
+		chipsTags.Add(container.NewHBox(creerChip("#"+tag, couleurChipTag), btnEnlever))
+	}
+
+	entryTag := widget.NewEntry()
+	entryTag.SetPlaceHolder("Ajouter un tag...")
+	ajouterTag := func() {
+		if strings.TrimSpace(entryTag.Text) == "" {
+			return
+		}
+		a.ajouterTag(id, entryTag.Text)
+		onChange()
+	}
+	entryTag.OnSubmitted = func(string) { ajouterTag() }
+	btnTag := widget.NewButtonWithIcon("", theme.ContentAddIcon(), ajouterTag)
+
+	return container.NewVBox(
+		labelCollections,
+		container.NewHScroll(checks),
+		container.NewBorder(nil, nil, nil, btnCollection, entryCollection),
+		labelTags,
+		container.NewHScroll(chipsTags),
+		container.NewBorder(nil, nil, nil, btnTag, entryTag),
+	)
+}
@@ -0,0 +1,525 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+
+	"groupie-tracker/geo"
+	"groupie-tracker/models"
+	"groupie-tracker/recherche"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// comparaison.go - la page qui compare 2 a 4 artistes cote a cote
+// avant pour comparer deux groupes (membres, annees, concerts, pays, tournees qui se croisent)
+// il fallait ouvrir leurs pages l'une apres l'autre et noter a cote
+// on choisit les artistes avec le bouton ⚖ des cards (ou de leur page), puis "Comparer" sur l'accueil:
+// une ligne par info, une carte commune avec une couleur par artiste, et les villes / dates en commun
+
+// le nb d'artistes qu'on peut comparer
+const (
+	minComparaison = 2
+	maxComparaison = 4
+)
+
+// couleursComparaison - la couleur de chaque artiste (colonne, points de la carte)
+var couleursComparaison = []color.Color{
+	color.NRGBA{R: 255, G: 80, B: 80, A: 255},
+	color.NRGBA{R: 80, G: 170, B: 255, A: 255},
+	color.NRGBA{R: 255, G: 200, B: 60, A: 255},
+	color.NRGBA{R: 130, G: 220, B: 120, A: 255},
+}
+
+// ecartPointsCarte - l'ecart en pixels entre les points de plusieurs artistes au meme endroit
+const ecartPointsCarte = 8
+
+// lieuCommun - un lieu ou au moins deux des artistes ont joue
+type lieuCommun struct {
+	Cle   string           // le lieu de l'API, pour ouvrir sa page
+	Dates map[int][]string // l'artiste -> ses dates la-bas, triees
+}
+
+// dateCommune - un jour ou au moins deux des artistes avaient un concert (pas forcement au meme endroit)
+type dateCommune struct {
+	Date  string
+	Lieux map[int]string // l'artiste -> ou il jouait ce jour-la
+}
+
+// dansComparaison - l'artiste est choisi pour la comparaison
+func (a *AppGroupie) dansComparaison(id int) bool {
+	for _, c := range a.comparaison {
+		if c == id {
+			return true
+		}
+	}
+	return false
+}
+
+// basculerComparaison - ajoute ou enleve un artiste de la comparaison
+// renvoie faux si on voulait l'ajouter mais qu'il y en a deja le max
+func (a *AppGroupie) basculerComparaison(id int) bool {
+	for i, c := range a.comparaison {
+		if c == id {
+			a.comparaison = append(a.comparaison[:i], a.comparaison[i+1:]...)
+			a.majBoutonComparaison()
+			return true
+		}
+	}
+	if len(a.comparaison) >= maxComparaison {
+		dialog.ShowInformation("Comparaison", fmt.Sprintf("On compare %d artistes max, enlève-en un d'abord", maxComparaison), a.fenetre)
+		return false
+	}
+	a.comparaison = append(a.comparaison, id)
+	a.majBoutonComparaison()
+	return true
+}
+
+// majBoutonComparaison - le bouton "Comparer" de l'accueil avec le nb d'artistes choisis
+func (a *AppGroupie) majBoutonComparaison() {
+	if a.btnComparaison == nil {
+		return
+	}
+	a.btnComparaison.SetText(fmt.Sprintf("⚖️ Comparer (%d)", len(a.comparaison)))
+	if len(a.comparaison) >= minComparaison {
+		a.btnComparaison.Enable()
+	} else {
+		a.btnComparaison.Disable()
+	}
+}
+
+// afficherComparaison - affiche la page de comparaison des artistes choisis
+func (a *AppGroupie) afficherComparaison() {
+	if len(a.comparaison) < minComparaison {
+		return
+	}
+	a.naviguer(EtatPage{Page: pageComparaison, Artistes: append([]int(nil), a.comparaison...)})
+}
+
+// lieuxEnCommun - les lieux ou au moins deux artistes ont joue,
+// ceux ou le plus d'artistes sont passes d'abord, puis par nom
+func lieuxEnCommun(fiches []recherche.Fiche) []lieuCommun {
+	parCle := make(map[string]*lieuCommun)
+	for _, f := range fiches {
+		for lieu, dates := range f.Concerts {
+			cle := geo.CleLieu(lieu)
+			lc, ok := parCle[cle]
+			if !ok {
+				lc = &lieuCommun{Cle: lieu, Dates: make(map[int][]string)}
+				parCle[cle] = lc
+			}
+			for _, d := range dates {
+				lc.Dates[f.Artiste.ID] = append(lc.Dates[f.Artiste.ID], strings.TrimPrefix(d, "*"))
+			}
+		}
+	}
+
+	var communs []lieuCommun
+	for _, lc := range parCle {
+		if len(lc.Dates) < 2 {
+			continue
+		}
+		for _, dates := range lc.Dates {
+			sort.SliceStable(dates, func(i, j int) bool {
+				return parserDate(dates[i]).Before(parserDate(dates[j]))
+			})
+		}
+		communs = append(communs, *lc)
+	}
+	sort.Slice(communs, func(i, j int) bool {
+		if len(communs[i].Dates) != len(communs[j].Dates) {
+			return len(communs[i].Dates) > len(communs[j].Dates)
+		}
+		return geo.CleLieu(communs[i].Cle) < geo.CleLieu(communs[j].Cle)
+	})
+	return communs
+}
+
+// datesEnCommun - les jours ou au moins deux artistes etaient en concert, du plus ancien au plus recent
+func datesEnCommun(fiches []recherche.Fiche) []dateCommune {
+	parDate := make(map[string]*dateCommune)
+	for _, f := range fiches {
+		for lieu, dates := range f.Concerts {
+			for _, d := range dates {
+				d = strings.TrimPrefix(d, "*")
+				dc, ok := parDate[d]
+				if !ok {
+					dc = &dateCommune{Date: d, Lieux: make(map[int]string)}
+					parDate[d] = dc
+				}
+				// deux concerts le meme jour (ca arrive pas mais bon): on garde le premier
+				if _, deja := dc.Lieux[f.Artiste.ID]; !deja {
+					dc.Lieux[f.Artiste.ID] = lieu
+				}
+			}
+		}
+	}
+
+	var communes []dateCommune
+	for _, dc := range parDate {
+		if len(dc.Lieux) >= 2 {
+			communes = append(communes, *dc)
+		}
+	}
+	sort.Slice(communes, func(i, j int) bool {
+		return parserDate(communes[i].Date).Before(parserDate(communes[j].Date))
+	})
+	return communes
+}
+
+// paysVisites - les pays ou l'artiste a joue, lisibles et tries
+func paysVisites(f recherche.Fiche) []string {
+	vus := make(map[string]bool)
+	var pays []string
+	for _, lieu := range f.Lieux {
+		_, p := geo.DecouperLieu(lieu)
+		if p == "" || vus[p] {
+			continue
+		}
+		vus[p] = true
+		pays = append(pays, majusculesMots(strings.ReplaceAll(p, "_", " ")))
+	}
+	sort.Strings(pays)
+	return pays
+}
+
+// periodeTournee - la date du premier et du dernier concert ("" si pas de concerts)
+func periodeTournee(f recherche.Fiche) (premier, dernier string) {
+	var t0, t1 time.Time
+	for _, dates := range f.Concerts {
+		for _, d := range dates {
+			t := parserDate(d)
+			if t.IsZero() {
+				continue
+			}
+			if t0.IsZero() || t.Before(t0) {
+				t0, premier = t, strings.TrimPrefix(d, "*")
+			}
+			if t1.IsZero() || t.After(t1) {
+				t1, dernier = t, strings.TrimPrefix(d, "*")
+			}
+		}
+	}
+	return premier, dernier
+}
+
+// pastilleCouleur - le petit rond de la couleur d'un artiste
+func pastilleCouleur(c color.Color) fyne.CanvasObject {
+	rond := canvas.NewRectangle(c)
+	rond.CornerRadius = 6
+	rond.SetMinSize(fyne.NewSize(12, 12))
+	return container.NewCenter(rond)
+}
+
+// ligneComparaison - une ligne de la comparaison: le titre puis une case par artiste
+// chaque ligne est sa propre grille, comme ca une liste de membres longue agrandit juste sa ligne
+func ligneComparaison(titre string, cases []fyne.CanvasObject) fyne.CanvasObject {
+	label := widget.NewLabel(titre)
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	return container.NewGridWithColumns(len(cases)+1, append([]fyne.CanvasObject{label}, cases...)...)
+}
+
+// labelComparaison - une case de texte qui revient a la ligne
+func labelComparaison(texte string) *widget.Label {
+	label := widget.NewLabel(texte)
+	label.Wrapping = fyne.TextWrapWord
+	return label
+}
+
+// creerPageComparaison - construit la page de comparaison des artistes
+// ctx est annule quand on quitte la page (les images et la carte arretent de charger)
+func (a *AppGroupie) creerPageComparaison(ctx context.Context, artistes []models.Artiste) fyne.CanvasObject {
+	boutonsNav := a.creerBoutonsNavigation()
+
+	titre := widget.NewLabel(fmt.Sprintf("⚖️ Comparaison de %d artistes", len(artistes)))
+	titre.TextStyle = fyne.TextStyle{Bold: true}
+
+	var btnLien *widget.Button
+	btnLien = widget.NewButton("🔗 Copier le lien", func() {
+		a.copierLienPage()
+		btnLien.SetText("✅ Lien copié")
+	})
+	header := container.NewHBox(boutonsNav, titre, layout.NewSpacer(), btnLien)
+
+	fiches := make([]recherche.Fiche, len(artistes))
+	for i, art := range artistes {
+		fiches[i] = a.ficheArtiste(art.ID)
+	}
+
+	// === LES LIGNES ===
+	// une case par artiste pour chaque ligne
+	cases := func(f func(i int, art models.Artiste) fyne.CanvasObject) []fyne.CanvasObject {
+		objets := make([]fyne.CanvasObject, len(artistes))
+		for i, art := range artistes {
+			objets[i] = f(i, art)
+		}
+		return objets
+	}
+
+	lignes := container.NewVBox(
+		ligneComparaison("", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			img := canvas.NewImageFromResource(theme.MediaPhotoIcon())
+			img.FillMode = canvas.ImageFillContain
+			img.SetMinSize(fyne.NewSize(120, 120))
+			artiste := art // capture pour la goroutine
+			go func() {
+				data, err := a.chargerMiniatureArtiste(ctx, artiste)
+				fyne.Do(func() {
+					if ctx.Err() != nil {
+						return
+					}
+					if err != nil || len(data) == 0 {
+						img.Resource = theme.BrokenImageIcon()
+					} else {
+						img.Resource = fyne.NewStaticResource(fmt.Sprintf("artist_%d", artiste.ID), data)
+					}
+					img.Refresh()
+				})
+			}()
+			return img
+		})),
+		ligneComparaison("🎤 Artiste", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			artiste := art // capture pour la closure
+			btn := widget.NewButton(artiste.Nom, func() {
+				a.ouvrirArtiste(artiste)
+			})
+			btn.Importance = widget.LowImportance
+			btn.Alignment = widget.ButtonAlignLeading
+			return container.NewBorder(nil, nil, pastilleCouleur(couleursComparaison[i]), nil, btn)
+		})),
+		ligneComparaison("📅 Création", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			return labelComparaison(fmt.Sprintf("%d", art.DateCreation))
+		})),
+		ligneComparaison("💿 Premier album", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			return labelComparaison(art.PremierAlbum)
+		})),
+		ligneComparaison("👥 Membres", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			return labelComparaison(fmt.Sprintf("%d\n%s", len(art.Membres), strings.Join(art.Membres, "\n")))
+		})),
+		ligneComparaison("🎵 Concerts", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			return labelComparaison(fmt.Sprintf("%d", fiches[i].NbConcerts()))
+		})),
+		ligneComparaison("🗓️ Tournée", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			premier, dernier := periodeTournee(fiches[i])
+			if premier == "" {
+				return labelComparaison("—")
+			}
+			return labelComparaison(premier + " → " + dernier)
+		})),
+		ligneComparaison("🌍 Pays visités", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			pays := paysVisites(fiches[i])
+			return labelComparaison(fmt.Sprintf("%d\n%s", len(pays), strings.Join(pays, ", ")))
+		})),
+		ligneComparaison("⭐ Ma note", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			if fiches[i].Etoiles == 0 {
+				return labelComparaison("—")
+			}
+			return labelComparaison(texteEtoiles(fiches[i].Etoiles))
+		})),
+		ligneComparaison("", cases(func(i int, art models.Artiste) fyne.CanvasObject {
+			id := art.ID // capture pour la closure
+			btn := widget.NewButtonWithIcon("Retirer", theme.ContentRemoveIcon(), func() {
+				a.retirerDeComparaison(id)
+			})
+			btn.Importance = widget.LowImportance
+			// en dessous de 2 y'a plus rien a comparer
+			if len(artistes) <= minComparaison {
+				btn.Disable()
+			}
+			return btn
+		})),
+	)
+
+	// === CARTE COMMUNE ===
+	legende := container.NewHBox()
+	for i, art := range artistes {
+		legende.Add(pastilleCouleur(couleursComparaison[i]))
+		legende.Add(widget.NewLabel(art.Nom))
+	}
+	carteContainer := container.NewVBox(widget.NewLabel("🗺️ Chargement de la carte..."))
+	go a.chargerCarteComparaison(ctx, fiches, carteContainer)
+
+	return container.NewVScroll(container.NewVBox(
+		header,
+		widget.NewSeparator(),
+		lignes,
+		widget.NewSeparator(),
+		a.creerSectionEnCommun(fiches),
+		widget.NewSeparator(),
+		legende,
+		carteContainer,
+	))
+}
+
+// retirerDeComparaison - enleve un artiste de la page affichee (et de la selection)
+func (a *AppGroupie) retirerDeComparaison(id int) {
+	if a.dansComparaison(id) {
+		a.basculerComparaison(id)
+	}
+	etat := &a.navigation.pages[a.navigation.position]
+	for i, c := range etat.Artistes {
+		if c == id {
+			etat.Artistes = append(etat.Artistes[:i:i], etat.Artistes[i+1:]...)
+			break
+		}
+	}
+	a.rafraichirPage()
+}
+
+// creerSectionEnCommun - les villes et les dates que les artistes ont en commun
+func (a *AppGroupie) creerSectionEnCommun(fiches []recherche.Fiche) fyne.CanvasObject {
+	noms := make(map[int]string, len(fiches))
+	for _, f := range fiches {
+		noms[f.Artiste.ID] = f.Artiste.Nom
+	}
+	// l'ordre des colonnes, pour que les artistes soient toujours cites pareil
+	ordre := func(ids map[int]bool) []int {
+		var tries []int
+		for _, f := range fiches {
+			if ids[f.Artiste.ID] {
+				tries = append(tries, f.Artiste.ID)
+			}
+		}
+		return tries
+	}
+
+	section := container.NewVBox()
+
+	// === VILLES ===
+	lieux := lieuxEnCommun(fiches)
+	titreLieux := widget.NewLabel(fmt.Sprintf("📍 Villes en commun (%d)", len(lieux)))
+	titreLieux.TextStyle = fyne.TextStyle{Bold: true}
+	section.Add(titreLieux)
+	if len(lieux) == 0 {
+		section.Add(widget.NewLabel("  Aucune ville en commun"))
+	}
+	for _, lc := range lieux {
+		cle := lc.Cle // capture pour la closure
+		btnLieu := widget.NewButton(formaterLieu(cle), func() {
+			a.afficherLieu(cle)
+		})
+		btnLieu.Importance = widget.LowImportance
+		btnLieu.Alignment = widget.ButtonAlignLeading
+
+		presents := make(map[int]bool, len(lc.Dates))
+		for id := range lc.Dates {
+			presents[id] = true
+		}
+		var morceaux []string
+		for _, id := range ordre(presents) {
+			morceaux = append(morceaux, noms[id]+": "+strings.Join(lc.Dates[id], ", "))
+		}
+		labelDates := widget.NewLabel(strings.Join(morceaux, "  —  "))
+		labelDates.Wrapping = fyne.TextWrapWord
+		section.Add(container.NewBorder(nil, nil, btnLieu, nil, labelDates))
+	}
+
+	// === DATES ===
+	dates := datesEnCommun(fiches)
+	titreDates := widget.NewLabel(fmt.Sprintf("📅 Dates en commun (%d)", len(dates)))
+	titreDates.TextStyle = fyne.TextStyle{Bold: true}
+	section.Add(titreDates)
+	if len(dates) == 0 {
+		section.Add(widget.NewLabel("  Aucune date en commun"))
+	}
+	for _, dc := range dates {
+		presents := make(map[int]bool, len(dc.Lieux))
+		cles := make(map[string]bool)
+		for id, lieu := range dc.Lieux {
+			presents[id] = true
+			cles[geo.CleLieu(lieu)] = true
+		}
+		var morceaux []string
+		for _, id := range ordre(presents) {
+			morceaux = append(morceaux, noms[id]+" à "+formaterLieu(dc.Lieux[id]))
+		}
+		ligne := "📅 " + dc.Date + "  —  " + strings.Join(morceaux, ", ")
+		if len(cles) == 1 {
+			ligne += "  🤝 même lieu"
+		}
+		label := widget.NewLabel(ligne)
+		label.Wrapping = fyne.TextWrapWord
+		section.Add(label)
+	}
+
+	return section
+}
+
+// chargerCarteComparaison - geocode les lieux de tous les artistes et dessine la carte commune
+// chaque lieu est geocode une seule fois, meme si plusieurs artistes y ont joue
+// (le rate limit de Nominatim est gere par geo); on arrete des que ctx est annule
+func (a *AppGroupie) chargerCarteComparaison(ctx context.Context, fiches []recherche.Fiche, carteContainer *fyne.Container) {
+	coords := make(map[string]models.Coordonnees)
+	rates := make(map[string]bool)
+	var points []PointCarte
+
+	for i, f := range fiches {
+		// toujours dans le meme ordre, sinon la carte bouge d'une fois sur l'autre
+		lieux := make([]string, 0, len(f.Concerts))
+		for lieu := range f.Concerts {
+			lieux = append(lieux, lieu)
+		}
+		sort.Strings(lieux)
+
+		for _, lieu := range lieux {
+			cle := geo.CleLieu(lieu)
+			if rates[cle] {
+				continue
+			}
+			c, ok := coords[cle]
+			if !ok {
+				var err error
+				c, err = geo.GeocoderLieuAPI(ctx, lieu)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					fmt.Printf("Geocoding echoue pour '%s': %v\n", geo.NettoyerLieu(lieu), err)
+					rates[cle] = true
+					continue
+				}
+				coords[cle] = c
+			}
+			points = append(points, PointCarte{Lieu: geo.NettoyerLieu(lieu), Cle: lieu, Coords: c, Couleur: couleursComparaison[i]})
+		}
+	}
+
+	// les points des artistes au meme endroit sont ecartes autour du lieu
+	parCle := make(map[string][]int)
+	for i, pt := range points {
+		parCle[geo.CleLieu(pt.Cle)] = append(parCle[geo.CleLieu(pt.Cle)], i)
+	}
+	for _, indices := range parCle {
+		for k, i := range indices {
+			points[i].Decalage = (float32(k) - float32(len(indices)-1)/2) * ecartPointsCarte
+		}
+	}
+
+	fyne.Do(func() {
+		if ctx.Err() != nil {
+			return
+		}
+		carteContainer.RemoveAll()
+		if len(points) == 0 {
+			carteContainer.Add(widget.NewLabel("🗺️ Aucun lieu géolocalisé"))
+		} else {
+			labelCarte := widget.NewLabel("🗺️ Carte des concerts:")
+			labelCarte.TextStyle = fyne.TextStyle{Bold: true}
+			carteContainer.Add(labelCarte)
+			carteContainer.Add(dessinerCarte(points, func(pt PointCarte) {
+				a.afficherLieu(pt.Cle)
+			}))
+		}
+		carteContainer.Refresh()
+	})
+}
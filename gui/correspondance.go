@@ -0,0 +1,85 @@
+package gui
+
+import (
+	"groupie-tracker/index"
+	"groupie-tracker/recherche"
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// correspondance.go - dit sur chaque card pourquoi l'artiste est dans les resultats
+// genre on tape "seattle" et la card de Nirvana affiche "📍 Seattle, Usa" avec "Seattle" en couleur
+
+// raisonCorrespondance - l'entree de l'index qui a fait matcher un artiste
+// + le morceau de texte libre qui l'a trouvee (pour savoir quoi surligner)
+type raisonCorrespondance struct {
+	index.Resultat
+	requete string
+}
+
+// les prefixes affiches selon le type d'entree (pour le nom on affiche rien, il est deja sur la card)
+var prefixesRaison = map[string]string{
+	index.TypeMembre:   "🎤 ",
+	index.TypeLieu:     "📍 ",
+	index.TypeConcert:  "🎫 concert ",
+	index.TypeCreation: "📅 création ",
+	index.TypeAlbum:    "💿 1er album ",
+}
+
+// raisonsRecherche - pour chaque artiste, la meilleure raison d'apparaitre dans la grille
+// on regarde tous les morceaux de texte libre de la requete, le premier qui trouve l'artiste gagne
+func (a *AppGroupie) raisonsRecherche(requete string) map[int]raisonCorrespondance {
+	raisons := make(map[int]raisonCorrespondance)
+	n, err := recherche.Analyser(requete)
+	if err != nil || n == nil {
+		return raisons
+	}
+	for _, libre := range recherche.TextesLibres(n) {
+		for id, r := range a.index.MeilleurParArtiste(libre) {
+			if _, ok := raisons[id]; !ok {
+				raisons[id] = raisonCorrespondance{Resultat: r, requete: libre}
+			}
+		}
+	}
+	return raisons
+}
+
+// creerLigneRaison - la petite ligne sous le nom, avec le passage qui matche en couleur
+// renvoie nil si y'a rien d'interessant a dire (match sur le nom)
+func creerLigneRaison(r raisonCorrespondance) fyne.CanvasObject {
+	prefixe, ok := prefixesRaison[r.Suggestion.Type]
+	if !ok {
+		return nil
+	}
+	valeur := r.Valeur
+	if r.Suggestion.Type == index.TypeLieu {
+		valeur = majusculesMots(valeur)
+	}
+
+	normal := widget.RichTextStyleInline
+	fort := widget.RichTextStyle{
+		ColorName: theme.ColorNamePrimary,
+		Inline:    true,
+		TextStyle: fyne.TextStyle{Bold: true},
+	}
+
+	segments := []widget.RichTextSegment{&widget.TextSegment{Text: prefixe, Style: normal}}
+	debut, fin := texte.Trouver(valeur, r.requete)
+	if debut == -1 {
+		// match approximatif (faute de frappe): pas de passage exact a surligner
+		segments = append(segments, &widget.TextSegment{Text: valeur + " ≈", Style: normal})
+	} else {
+		segments = append(segments,
+			&widget.TextSegment{Text: valeur[:debut], Style: normal},
+			&widget.TextSegment{Text: valeur[debut:fin], Style: fort},
+			&widget.TextSegment{Text: valeur[fin:], Style: normal},
+		)
+	}
+
+	ligne := widget.NewRichText(segments...)
+	ligne.Wrapping = fyne.TextWrapWord
+	return ligne
+}
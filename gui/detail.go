@@ -1,10 +1,10 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 	"image/color"
 	"strings"
-	"time"
 
 	"groupie-tracker/api"
 	"groupie-tracker/geo"
@@ -13,6 +13,7 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
@@ -23,15 +24,32 @@ import (
 // PointCarte - un point a afficher sur la carte avec son nom et ses coordonnees
 type PointCarte struct {
 	Lieu   string
+	Cle    string // le lieu tel que l'API le donne ("london-uk"), pour ouvrir sa page
 	Coords models.Coordonnees
+
+	// pour la carte de la comparaison: la couleur de l'artiste (nil = rouge) et un decalage
+	// en pixels pour que les points de plusieurs artistes au meme endroit se cachent pas
+	Couleur  color.Color
+	Decalage float32
 }
 
 // creerPageDetail - construit la page complete de detail d'un artiste
-func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
-	// bouton retour
-	btnRetour := a.creerBoutonRetour()
+// onglet est nil pour la page principale; dans un onglet a part y'a pas de precedent / suivant
+// et on reconstruit juste l'onglet quand quelque chose change
+// ctx est annule quand on quitte la page (les concerts et la carte arretent de charger)
+func (a *AppGroupie) creerPageDetail(ctx context.Context, artiste models.Artiste, onglet *ongletArtiste) fyne.CanvasObject {
+	rafraichir := a.rafraichirPage
+	if onglet != nil {
+		rafraichir = func() { a.construireOnglet(onglet) }
+	}
 
-	// header avec bouton retour et titre
+	// boutons precedent / suivant
+	var boutonsNav fyne.CanvasObject = layout.NewSpacer()
+	if onglet == nil {
+		boutonsNav = a.creerBoutonsNavigation()
+	}
+
+	// header avec les boutons de navigation et titre
 	titreDetail := widget.NewLabel("🎤 " + artiste.Nom)
 	titreDetail.TextStyle = fyne.TextStyle{Bold: true}
 
@@ -43,10 +61,28 @@ func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
 	btnFav := widget.NewButton(etoileTxt, func() {
 		a.toggleFavori(artiste.ID)
 		// on rafraichit la page pour mettre a jour le bouton
-		a.afficherDetail(artiste)
+		rafraichir()
 	})
 
-	headerDetail := container.NewHBox(btnRetour, titreDetail, layout.NewSpacer(), btnFav)
+	// le lien groupie:// de l'artiste, pour le coller dans nos docs
+	var btnLien *widget.Button
+	btnLien = widget.NewButton("🔗 Copier le lien", func() {
+		a.copierLien(a.lienPage(EtatPage{Page: pageArtiste, ArtisteID: artiste.ID}))
+		btnLien.SetText("✅ Lien copié")
+	})
+
+	// choisir l'artiste pour la comparaison (on compare depuis l'accueil)
+	comparerTxt := "⚖️ Comparer"
+	if a.dansComparaison(artiste.ID) {
+		comparerTxt = "⚖️ Retirer de la comparaison"
+	}
+	btnComparer := widget.NewButton(comparerTxt, func() {
+		if a.basculerComparaison(artiste.ID) {
+			rafraichir()
+		}
+	})
+
+	headerDetail := container.NewHBox(boutonsNav, titreDetail, layout.NewSpacer(), btnLien, btnComparer, btnFav)
 
 	// === SECTION IMAGE ===
 	var imgArtiste *canvas.Image
@@ -63,14 +99,10 @@ func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
 	labelAlbum := widget.NewLabel(fmt.Sprintf("💿 Premier album: %s", artiste.PremierAlbum))
 	labelNbMembres := widget.NewLabel(fmt.Sprintf("👥 Nombre de membres: %d", len(artiste.Membres)))
 
-	// liste des membres
+	// liste des membres (cliquables, ca ouvre la page du membre)
 	labelMembresTitle := widget.NewLabel("🎸 Membres:")
 	labelMembresTitle.TextStyle = fyne.TextStyle{Bold: true}
-	membresContainer := container.NewVBox(labelMembresTitle)
-	for _, membre := range artiste.Membres {
-		m := strings.TrimSpace(membre)
-		membresContainer.Add(widget.NewLabel("  • " + m))
-	}
+	membresContainer := container.NewVBox(labelMembresTitle, a.creerListeMembres(artiste))
 
 	infosContainer := container.NewVBox(
 		labelCreation,
@@ -80,6 +112,12 @@ func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
 		membresContainer,
 	)
 
+	// === SECTION COLLECTIONS ET TAGS ===
+	classement := a.creerSectionClassement(artiste.ID, func() {
+		// on rafraichit la page pour voir les changements
+		rafraichir()
+	})
+
 	// la partie haute: image a gauche, infos a droite
 	var partieHaute fyne.CanvasObject
 	if imgArtiste != nil {
@@ -106,33 +144,25 @@ func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
 	go func() {
 		relation, err := api.RecupererRelation(artiste.ID)
 		if err != nil {
-			concertsContainer.RemoveAll()
-			concertsContainer.Add(widget.NewLabel(fmt.Sprintf("❌ Erreur: %v", err)))
-			concertsContainer.Refresh()
+			fyne.Do(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				concertsContainer.RemoveAll()
+				concertsContainer.Add(widget.NewLabel(fmt.Sprintf("❌ Erreur: %v", err)))
+				concertsContainer.Refresh()
+			})
 			return
 		}
 
-		// afficher les concerts
-		concertsContainer.RemoveAll()
-		labelConcerts := widget.NewLabel("🎵 Concerts:")
-		labelConcerts.TextStyle = fyne.TextStyle{Bold: true}
-		concertsContainer.Add(labelConcerts)
-
-		if len(relation.DatesLocations) == 0 {
-			concertsContainer.Add(widget.NewLabel("  Aucun concert trouvé"))
-		} else {
-			for lieu, dates := range relation.DatesLocations {
-				lieuPropre := formaterLieu(lieu)
-				for _, date := range dates {
-					labelConcert := widget.NewLabel(fmt.Sprintf("  📍 %s  —  📅 %s", lieuPropre, date))
-					concertsContainer.Add(labelConcert)
-				}
+		fyne.Do(func() {
+			if ctx.Err() == nil {
+				a.afficherConcerts(relation, concertsContainer)
 			}
-		}
-		concertsContainer.Refresh()
+		})
 
 		// maintenant on fait la carte avec les geocoords
-		a.chargerCarte(relation, carteContainer)
+		a.chargerCarte(ctx, relation, carteContainer)
 	}()
 
 	// assembler la page complete
@@ -141,6 +171,10 @@ func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
 		widget.NewSeparator(),
 		partieHaute,
 		widget.NewSeparator(),
+		classement,
+		widget.NewSeparator(),
+		a.creerSectionNotes(artiste.ID),
+		widget.NewSeparator(),
 		concertsContainer,
 		widget.NewSeparator(),
 		carteContainer,
@@ -152,6 +186,33 @@ func (a *AppGroupie) creerPageDetail(artiste models.Artiste) fyne.CanvasObject {
 	return scroll
 }
 
+// afficherConcerts - la liste des concerts d'un artiste, un clic sur un concert ouvre son lieu
+func (a *AppGroupie) afficherConcerts(relation models.Relation, concertsContainer *fyne.Container) {
+	concertsContainer.RemoveAll()
+	labelConcerts := widget.NewLabel("🎵 Concerts:")
+	labelConcerts.TextStyle = fyne.TextStyle{Bold: true}
+	concertsContainer.Add(labelConcerts)
+
+	if len(relation.DatesLocations) == 0 {
+		concertsContainer.Add(widget.NewLabel("  Aucun concert trouvé"))
+	} else {
+		for lieu, dates := range relation.DatesLocations {
+			lieuPropre := formaterLieu(lieu)
+			lieuAPI := lieu // capture pour la closure
+			for _, date := range dates {
+				// un clic sur un concert ouvre la page du lieu
+				btnConcert := widget.NewButton(fmt.Sprintf("📍 %s  —  📅 %s", lieuPropre, date), func() {
+					a.afficherLieu(lieuAPI)
+				})
+				btnConcert.Importance = widget.LowImportance
+				btnConcert.Alignment = widget.ButtonAlignLeading
+				concertsContainer.Add(btnConcert)
+			}
+		}
+	}
+	concertsContainer.Refresh()
+}
+
 // formaterLieu - formate un lieu de l'API en quelque chose de lisible
 // "north_carolina-usa" -> "North Carolina, Usa"
 func formaterLieu(lieu string) string {
@@ -160,8 +221,12 @@ func formaterLieu(lieu string) string {
 	if idx != -1 {
 		lieu = lieu[:idx] + ", " + lieu[idx+1:]
 	}
-	// mettre chaque mot en majuscule (fait main car strings.Title est deprecated)
-	mots := strings.Fields(lieu)
+	return majusculesMots(lieu)
+}
+
+// majusculesMots - met chaque mot en majuscule (fait main car strings.Title est deprecated)
+func majusculesMots(texte string) string {
+	mots := strings.Fields(texte)
 	for i, mot := range mots {
 		if len(mot) > 0 {
 			mots[i] = strings.ToUpper(mot[:1]) + mot[1:]
@@ -171,22 +236,33 @@ func formaterLieu(lieu string) string {
 }
 
 // chargerCarte - geocode les lieux et dessine la carte
-func (a *AppGroupie) chargerCarte(relation models.Relation, carteContainer *fyne.Container) {
+// le rate limit de Nominatim est gere par geo (partage avec les autres pages);
+// si on quitte la page pendant le geocoding on arrete et on touche plus a la carte
+func (a *AppGroupie) chargerCarte(ctx context.Context, relation models.Relation, carteContainer *fyne.Container) {
 	var points []PointCarte
 
 	for lieu := range relation.DatesLocations {
 		lieuPropre := geo.NettoyerLieu(lieu)
-		coords, err := geo.GeocoderLieuAPI(lieu)
+		coords, err := geo.GeocoderLieuAPI(ctx, lieu)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			fmt.Printf("Geocoding echoue pour '%s': %v\n", lieuPropre, err)
 			continue
 		}
-		points = append(points, PointCarte{Lieu: lieuPropre, Coords: coords})
-		// on attend un peu entre chaque requete pour respecter le rate limit de Nominatim
-		time.Sleep(1100 * time.Millisecond)
+		points = append(points, PointCarte{Lieu: lieuPropre, Cle: lieu, Coords: coords})
 	}
 
-	// afficher la carte
+	fyne.Do(func() {
+		if ctx.Err() == nil {
+			a.afficherCarte(points, carteContainer)
+		}
+	})
+}
+
+// afficherCarte - la carte des concerts avec sa legende
+func (a *AppGroupie) afficherCarte(points []PointCarte, carteContainer *fyne.Container) {
 	carteContainer.RemoveAll()
 
 	if len(points) == 0 {
@@ -199,8 +275,10 @@ func (a *AppGroupie) chargerCarte(relation models.Relation, carteContainer *fyne
 	labelCarte.TextStyle = fyne.TextStyle{Bold: true}
 	carteContainer.Add(labelCarte)
 
-	// on dessine une carte simple avec les points
-	carteWidget := dessinerCarte(points)
+	// on dessine une carte simple avec les points (un clic sur un point ouvre la page du lieu)
+	carteWidget := dessinerCarte(points, func(pt PointCarte) {
+		a.afficherLieu(pt.Cle)
+	})
 	carteContainer.Add(carteWidget)
 
 	// on ajoute la legende en dessous
@@ -212,12 +290,50 @@ func (a *AppGroupie) chargerCarte(relation models.Relation, carteContainer *fyne
 	carteContainer.Refresh()
 }
 
+// vueCarte - la portion du monde qu'on dessine, en degres
+type vueCarte struct {
+	lngMin, lngMax float64
+	latMin, latMax float64
+}
+
+// vueMonde - le monde entier, pour la carte des concerts d'un artiste
+var vueMonde = vueCarte{lngMin: -180, lngMax: 180, latMin: -90, latMax: 90}
+
+// vueAutour - une vue centree sur un point, demiLng / demiLat degres de chaque cote
+// (on la decale si elle deborde du monde, pour garder le point dedans)
+func vueAutour(c models.Coordonnees, demiLng, demiLat float64) vueCarte {
+	v := vueCarte{lngMin: c.Lng - demiLng, lngMax: c.Lng + demiLng, latMin: c.Lat - demiLat, latMax: c.Lat + demiLat}
+	if v.lngMin < -180 {
+		v.lngMin, v.lngMax = -180, -180+2*demiLng
+	}
+	if v.lngMax > 180 {
+		v.lngMin, v.lngMax = 180-2*demiLng, 180
+	}
+	if v.latMin < -90 {
+		v.latMin, v.latMax = -90, -90+2*demiLat
+	}
+	if v.latMax > 90 {
+		v.latMin, v.latMax = 90-2*demiLat, 90
+	}
+	return v
+}
+
+// projeter - conversion lat/lng en position x/y (projection plate simple)
+func (v vueCarte) projeter(lat, lng float64, largeur, hauteur float32) (float32, float32) {
+	x := float32((lng - v.lngMin) / (v.lngMax - v.lngMin) * float64(largeur))
+	y := float32((v.latMax - lat) / (v.latMax - v.latMin) * float64(hauteur))
+	return x, y
+}
+
 // dessinerCarte - dessine une carte du monde simple avec des points rouges
 // c'est un canvas custom qui fait une projection plate des coordonnees
-func dessinerCarte(points []PointCarte) fyne.CanvasObject {
-	largeur := float32(700)
-	hauteur := float32(400)
+// onTap est appele quand on clique un point (nil = points pas cliquables)
+func dessinerCarte(points []PointCarte, onTap func(PointCarte)) fyne.CanvasObject {
+	return dessinerCarteVue(points, vueMonde, 700, 400, onTap)
+}
 
+// dessinerCarteVue - pareil mais seulement la portion vue du monde, a la taille demandee
+func dessinerCarteVue(points []PointCarte, vue vueCarte, largeur, hauteur float32, onTap func(PointCarte)) fyne.CanvasObject {
 	// le fond de la carte (rectangle bleu fonce pour l'ocean)
 	fond := canvas.NewRectangle(color.RGBA{R: 20, G: 30, B: 50, A: 255})
 	fond.SetMinSize(fyne.NewSize(largeur, hauteur))
@@ -226,8 +342,9 @@ func dessinerCarte(points []PointCarte) fyne.CanvasObject {
 	elements := []fyne.CanvasObject{fond}
 
 	// des rectangles verts pour representer les continents (c'est simplifie)
+	// les positions sont en fraction du monde entier
 	continentsData := []struct {
-		x, y, w, h float32
+		x, y, w, h float64
 	}{
 		{0.10, 0.15, 0.15, 0.20}, // amerique du nord
 		{0.18, 0.40, 0.08, 0.25}, // amerique du sud
@@ -238,28 +355,39 @@ func dessinerCarte(points []PointCarte) fyne.CanvasObject {
 	}
 
 	for _, c := range continentsData {
+		// on repasse en degres, puis dans la vue, en coupant ce qui depasse
+		x1, y1 := vue.projeter(90-c.y*180, c.x*360-180, largeur, hauteur)
+		x2, y2 := vue.projeter(90-(c.y+c.h)*180, (c.x+c.w)*360-180, largeur, hauteur)
+		x1, x2 = max(x1, 0), min(x2, largeur)
+		y1, y2 = max(y1, 0), min(y2, hauteur)
+		if x2 <= x1 || y2 <= y1 {
+			continue // pas dans la vue
+		}
 		rect := canvas.NewRectangle(color.RGBA{R: 60, G: 100, B: 60, A: 150})
-		rect.Resize(fyne.NewSize(largeur*c.w, hauteur*c.h))
-		rect.Move(fyne.NewPos(largeur*c.x, hauteur*c.y))
+		rect.Resize(fyne.NewSize(x2-x1, y2-y1))
+		rect.Move(fyne.NewPos(x1, y1))
 		elements = append(elements, rect)
 	}
 
-	// on dessine l'equateur et le meridien pour orienter
-	equateur := canvas.NewLine(color.RGBA{R: 100, G: 100, B: 100, A: 100})
-	equateur.Position1 = fyne.NewPos(0, hauteur/2)
-	equateur.Position2 = fyne.NewPos(largeur, hauteur/2)
-	elements = append(elements, equateur)
-
-	meridien := canvas.NewLine(color.RGBA{R: 100, G: 100, B: 100, A: 100})
-	meridien.Position1 = fyne.NewPos(largeur/2, 0)
-	meridien.Position2 = fyne.NewPos(largeur/2, hauteur)
-	elements = append(elements, meridien)
+	// on dessine l'equateur et le meridien pour orienter (s'ils sont dans la vue)
+	if vue.latMin < 0 && vue.latMax > 0 {
+		_, y := vue.projeter(0, 0, largeur, hauteur)
+		equateur := canvas.NewLine(color.RGBA{R: 100, G: 100, B: 100, A: 100})
+		equateur.Position1 = fyne.NewPos(0, y)
+		equateur.Position2 = fyne.NewPos(largeur, y)
+		elements = append(elements, equateur)
+	}
+	if vue.lngMin < 0 && vue.lngMax > 0 {
+		x, _ := vue.projeter(0, 0, largeur, hauteur)
+		meridien := canvas.NewLine(color.RGBA{R: 100, G: 100, B: 100, A: 100})
+		meridien.Position1 = fyne.NewPos(x, 0)
+		meridien.Position2 = fyne.NewPos(x, hauteur)
+		elements = append(elements, meridien)
+	}
 
 	// ajouter les points de concert sur la carte
 	for _, pt := range points {
-		// conversion lat/lng en position x/y (projection plate simple)
-		x := float32((pt.Coords.Lng + 180) / 360 * float64(largeur))
-		y := float32((90 - pt.Coords.Lat) / 180 * float64(hauteur))
+		x, y := vue.projeter(pt.Coords.Lat, pt.Coords.Lng, largeur, hauteur)
 
 		// clamp pour rester dans les bornes
 		if x < 0 {
@@ -275,17 +403,10 @@ func dessinerCarte(points []PointCarte) fyne.CanvasObject {
 			y = hauteur
 		}
 
-		// halo lumineux autour du point
-		halo := canvas.NewCircle(color.RGBA{R: 255, G: 100, B: 100, A: 80})
-		halo.Resize(fyne.NewSize(20, 20))
-		halo.Move(fyne.NewPos(x-10, y-10))
-		elements = append(elements, halo)
-
-		// le point rouge du concert
-		point := canvas.NewCircle(color.RGBA{R: 255, G: 50, B: 50, A: 255})
-		point.Resize(fyne.NewSize(10, 10))
-		point.Move(fyne.NewPos(x-5, y-5))
-		elements = append(elements, point)
+		marqueur := newMarqueurCarte(pt, onTap)
+		marqueur.Resize(fyne.NewSize(20, 20))
+		marqueur.Move(fyne.NewPos(x-10+pt.Decalage, y-10))
+		elements = append(elements, marqueur)
 	}
 
 	carte := container.NewWithoutLayout(elements...)
@@ -293,3 +414,50 @@ func dessinerCarte(points []PointCarte) fyne.CanvasObject {
 
 	return container.NewStack(carte)
 }
+
+// marqueurCarte - un point de concert sur la carte, on peut cliquer dessus
+type marqueurCarte struct {
+	widget.BaseWidget
+	point PointCarte
+	onTap func(PointCarte)
+}
+
+func newMarqueurCarte(point PointCarte, onTap func(PointCarte)) *marqueurCarte {
+	m := &marqueurCarte{point: point, onTap: onTap}
+	m.ExtendBaseWidget(m)
+	return m
+}
+
+func (m *marqueurCarte) CreateRenderer() fyne.WidgetRenderer {
+	// le point rouge du concert, ou de la couleur de l'artiste s'il en a une
+	var couleur color.Color = color.RGBA{R: 255, G: 50, B: 50, A: 255}
+	if m.point.Couleur != nil {
+		couleur = m.point.Couleur
+	}
+
+	// halo lumineux autour du point
+	r, g, b, _ := couleur.RGBA()
+	halo := canvas.NewCircle(color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 80})
+	halo.Resize(fyne.NewSize(20, 20))
+
+	point := canvas.NewCircle(couleur)
+	point.Resize(fyne.NewSize(10, 10))
+	point.Move(fyne.NewPos(5, 5))
+
+	return widget.NewSimpleRenderer(container.NewWithoutLayout(halo, point))
+}
+
+// Tapped - clic sur le point
+func (m *marqueurCarte) Tapped(*fyne.PointEvent) {
+	if m.onTap != nil {
+		m.onTap(m.point)
+	}
+}
+
+// Cursor - la petite main quand le point est cliquable
+func (m *marqueurCarte) Cursor() desktop.Cursor {
+	if m.onTap != nil {
+		return desktop.PointerCursor
+	}
+	return desktop.DefaultCursor
+}
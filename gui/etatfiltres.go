@@ -0,0 +1,176 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2/data/binding"
+)
+
+// etatfiltres.go - l'etat des filtres, bindable avec le data binding de Fyne
+// tous les widgets du panneau ecoutent cet etat et se mettent a jour tout seuls,
+// comme ca le reset (ou un undo) remet vraiment les sliders et les checkboxes a jour
+// on garde aussi un historique pour pouvoir annuler / refaire
+
+// tailleMaxHistorique - on garde pas un historique infini non plus
+const tailleMaxHistorique = 100
+
+// EtatFiltres - les filtres courants + l'historique pour undo/redo
+type EtatFiltres struct {
+	donnee       binding.Item[Filtres]
+	bornes       BornesFiltres
+	historique   []Filtres // chaque etat est une copie independante
+	position     int       // l'index de l'etat courant dans l'historique
+	dernierChamp string    // pour regrouper les modifs d'un meme drag de slider en une seule etape
+}
+
+// NewEtatFiltres - cree un etat avec les filtres par defaut
+func NewEtatFiltres(bornes BornesFiltres) *EtatFiltres {
+	initial := *NewFiltres(bornes)
+	e := &EtatFiltres{
+		donnee:     binding.NewItem(filtresEgaux),
+		bornes:     bornes,
+		historique: []Filtres{initial},
+	}
+	e.donnee.Set(initial.Cloner())
+	return e
+}
+
+// Cloner - copie profonde des filtres (les maps sont recopiees)
+func (f Filtres) Cloner() Filtres {
+	copie := f
+	copie.NbMembres = make(map[int]bool, len(f.NbMembres))
+	for k, v := range f.NbMembres {
+		copie.NbMembres[k] = v
+	}
+	copie.Locations = make(map[string]bool, len(f.Locations))
+	for k, v := range f.Locations {
+		copie.Locations[k] = v
+	}
+	copie.Collections = make(map[string]bool, len(f.Collections))
+	for k, v := range f.Collections {
+		copie.Collections[k] = v
+	}
+	copie.Tags = make(map[string]bool, len(f.Tags))
+	for k, v := range f.Tags {
+		copie.Tags[k] = v
+	}
+	return copie
+}
+
+// filtresEgaux - compare deux etats de filtres, sert au binding pour pas notifier pour rien
+func filtresEgaux(a, b Filtres) bool {
+	if a.CreationMin != b.CreationMin || a.CreationMax != b.CreationMax ||
+		a.AlbumMin != b.AlbumMin || a.AlbumMax != b.AlbumMax {
+		return false
+	}
+	if len(a.NbMembres) != len(b.NbMembres) || len(a.Locations) != len(b.Locations) ||
+		len(a.Collections) != len(b.Collections) || len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for k := range a.NbMembres {
+		if !b.NbMembres[k] {
+			return false
+		}
+	}
+	for k := range a.Locations {
+		if !b.Locations[k] {
+			return false
+		}
+	}
+	for k := range a.Collections {
+		if !b.Collections[k] {
+			return false
+		}
+	}
+	for k := range a.Tags {
+		if !b.Tags[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// Filtres - retourne une copie des filtres courants (on peut la lire sans risque)
+func (e *EtatFiltres) Filtres() Filtres {
+	f, _ := e.donnee.Get()
+	return f.Cloner()
+}
+
+// Modifier - applique une modif aux filtres et l'ajoute a l'historique
+// champ sert a regrouper les modifs successives d'un meme widget (un drag de slider = une etape),
+// jusqu'a ce que FinirModif soit appele
+func (e *EtatFiltres) Modifier(champ string, modif func(f *Filtres)) {
+	nouveau := e.Filtres()
+	modif(&nouveau)
+	if filtresEgaux(nouveau, e.historique[e.position]) {
+		return
+	}
+
+	// une nouvelle modif efface tout ce qu'on pouvait refaire
+	e.historique = e.historique[:e.position+1]
+	if champ != "" && champ == e.dernierChamp && e.position > 0 {
+		e.historique[e.position] = nouveau
+	} else {
+		e.historique = append(e.historique, nouveau)
+		if len(e.historique) > tailleMaxHistorique {
+			e.historique = e.historique[1:]
+		}
+		e.position = len(e.historique) - 1
+	}
+	e.dernierChamp = champ
+	e.donnee.Set(nouveau.Cloner())
+}
+
+// FinirModif - le drag est fini: la prochaine modif du meme champ sera une nouvelle etape
+// (sinon deux drags du meme slider l'un apres l'autre s'annulaient d'un coup)
+func (e *EtatFiltres) FinirModif() {
+	e.dernierChamp = ""
+}
+
+// Remplacer - met tous les filtres d'un coup (pour un reset ou un preset), ca compte comme une etape
+func (e *EtatFiltres) Remplacer(f Filtres) {
+	e.dernierChamp = ""
+	e.Modifier("", func(courant *Filtres) {
+		*courant = f.Cloner()
+	})
+}
+
+// Reset - remet tous les filtres aux bornes des donnees
+func (e *EtatFiltres) Reset() {
+	e.Remplacer(*NewFiltres(e.bornes))
+}
+
+// PeutAnnuler - y'a quelque chose a annuler ?
+func (e *EtatFiltres) PeutAnnuler() bool {
+	return e.position > 0
+}
+
+// PeutRefaire - y'a quelque chose a refaire ?
+func (e *EtatFiltres) PeutRefaire() bool {
+	return e.position < len(e.historique)-1
+}
+
+// Annuler - revient a l'etat d'avant
+func (e *EtatFiltres) Annuler() {
+	if !e.PeutAnnuler() {
+		return
+	}
+	e.position--
+	e.dernierChamp = ""
+	e.donnee.Set(e.historique[e.position].Cloner())
+}
+
+// Refaire - repasse a l'etat d'apres
+func (e *EtatFiltres) Refaire() {
+	if !e.PeutRefaire() {
+		return
+	}
+	e.position++
+	e.dernierChamp = ""
+	e.donnee.Set(e.historique[e.position].Cloner())
+}
+
+// AjouterEcouteur - appelle fn a chaque changement des filtres (et une fois tout de suite)
+func (e *EtatFiltres) AjouterEcouteur(fn func()) binding.DataListener {
+	ecouteur := binding.NewDataListener(fn)
+	e.donnee.AddListener(ecouteur)
+	return ecouteur
+}
@@ -0,0 +1,177 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// favoris.go - les favoris de l'utilisateur, gardes dans les preferences Fyne
+// avant c'etait juste une map en memoire, tout etait perdu a la fermeture de l'app
+// on peut aussi les exporter / importer en JSON pour partager une liste entre collegues
+
+// la cle dans les preferences Fyne
+const clePrefFavoris = "favoris"
+
+// FavoriExporte - un favori dans le fichier d'export
+// on met le nom en plus de l'ID: si l'API renumerote un jour, l'import retrouve quand meme l'artiste
+type FavoriExporte struct {
+	ID  int    `json:"id"`
+	Nom string `json:"nom"`
+}
+
+// chargerFavoris - lit les favoris sauves (au lancement)
+func (a *AppGroupie) chargerFavoris() {
+	var ids []int
+	chargerPrefJSON(a.app.Preferences(), clePrefFavoris, &ids)
+
+	a.favorisMu.Lock()
+	a.favoris = make(map[int]bool, len(ids))
+	for _, id := range ids {
+		a.favoris[id] = true
+	}
+	a.favorisMu.Unlock()
+}
+
+// sauverFavoris - ecrit les favoris dans les preferences
+func (a *AppGroupie) sauverFavoris() {
+	sauverPrefJSON(a.app.Preferences(), clePrefFavoris, a.listeFavoris())
+}
+
+// listeFavoris - les IDs des favoris, tries pour que la preference change pas pour rien
+func (a *AppGroupie) listeFavoris() []int {
+	a.favorisMu.RLock()
+	ids := make([]int, 0, len(a.favoris))
+	for id := range a.favoris {
+		ids = append(ids, id)
+	}
+	a.favorisMu.RUnlock()
+	sort.Ints(ids)
+	return ids
+}
+
+// toggleFavori - ajoute ou enleve un artiste des favoris (et sauve direct)
+func (a *AppGroupie) toggleFavori(id int) {
+	a.favorisMu.Lock()
+	if a.favoris[id] {
+		delete(a.favoris, id)
+	} else {
+		a.favoris[id] = true
+	}
+	a.favorisMu.Unlock()
+	a.sauverFavoris()
+}
+
+// estFavori - verifie si un artiste est dans les favoris
+func (a *AppGroupie) estFavori(id int) bool {
+	a.favorisMu.RLock()
+	defer a.favorisMu.RUnlock()
+	return a.favoris[id]
+}
+
+// exporterFavoris - les favoris avec leur nom, dans l'ordre des IDs
+func (a *AppGroupie) exporterFavoris() []FavoriExporte {
+	var exportes []FavoriExporte
+	for _, id := range a.listeFavoris() {
+		if art, ok := a.artisteParID(id); ok {
+			exportes = append(exportes, FavoriExporte{ID: art.ID, Nom: art.Nom})
+		}
+	}
+	return exportes
+}
+
+// importerFavoris - ajoute les favoris importes a ceux qu'on a deja (on enleve rien)
+// un favori est retrouve par son ID si le nom colle, sinon par son nom
+// renvoie le nb d'artistes ajoutes et ceux qu'on a pas trouves
+func (a *AppGroupie) importerFavoris(importes []FavoriExporte) (ajoutes int, introuvables []string) {
+	parNom := make(map[string]int, len(a.artistes))
+	for _, art := range a.artistes {
+		parNom[texte.Normaliser(art.Nom)] = art.ID
+	}
+
+	a.favorisMu.Lock()
+	for _, f := range importes {
+		id, ok := 0, false
+		if art, trouve := a.artisteParID(f.ID); trouve && (f.Nom == "" || texte.Normaliser(art.Nom) == texte.Normaliser(f.Nom)) {
+			id, ok = art.ID, true
+		} else {
+			id, ok = parNom[texte.Normaliser(f.Nom)]
+		}
+		if !ok {
+			introuvables = append(introuvables, f.Nom)
+			continue
+		}
+		if !a.favoris[id] {
+			a.favoris[id] = true
+			ajoutes++
+		}
+	}
+	a.favorisMu.Unlock()
+
+	a.sauverFavoris()
+	return ajoutes, introuvables
+}
+
+// afficherFavoris - affiche la page des favoris
+func (a *AppGroupie) afficherFavoris() {
+	a.naviguer(EtatPage{Page: pageFavoris})
+}
+
+// creerPageFavoris - les cards des favoris, avec l'export / import
+// une card qu'on retire des favoris reste affichee jusqu'au prochain passage sur la page,
+// comme ca on peut la remettre si on a clique trop vite
+// ctx est annule quand on quitte la page (les images des cards arretent de charger)
+func (a *AppGroupie) creerPageFavoris(ctx context.Context) fyne.CanvasObject {
+	boutonsNav := a.creerBoutonsNavigation()
+
+	ids := a.listeFavoris()
+	titre := widget.NewLabel(fmt.Sprintf("⭐ Favoris (%d)", len(ids)))
+	titre.TextStyle = fyne.TextStyle{Bold: true}
+
+	btnExporter := widget.NewButtonWithIcon("Exporter", theme.UploadIcon(), func() {
+		exporterJSON(a.fenetre, "favoris-groupie.json", a.exporterFavoris())
+	})
+	btnImporter := widget.NewButtonWithIcon("Importer", theme.DownloadIcon(), func() {
+		var importes []FavoriExporte
+		importerJSON(a.fenetre, &importes, func() {
+			ajoutes, introuvables := a.importerFavoris(importes)
+			message := fmt.Sprintf("%d favori(s) ajouté(s)", ajoutes)
+			if len(introuvables) > 0 {
+				message += fmt.Sprintf("\n%d artiste(s) introuvable(s)", len(introuvables))
+			}
+			dialog.ShowInformation("Import des favoris", message, a.fenetre)
+			a.rafraichirPage()
+		})
+	})
+	header := container.NewHBox(boutonsNav, titre, layout.NewSpacer(), btnExporter, btnImporter)
+
+	grille := container.NewGridWrap(tailleCarte)
+	for _, id := range ids {
+		if art, ok := a.artisteParID(id); ok {
+			grille.Add(a.creerCardArtiste(ctx, art))
+		}
+	}
+
+	var corps fyne.CanvasObject = grille
+	if len(ids) == 0 {
+		vide := widget.NewLabel("Pas encore de favoris, clique sur ☆ sur un artiste pour l'ajouter")
+		vide.Alignment = fyne.TextAlignCenter
+		corps = vide
+	}
+
+	contenu := container.NewVBox(
+		header,
+		widget.NewSeparator(),
+		corps,
+	)
+	return container.NewVScroll(contenu)
+}
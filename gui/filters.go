@@ -2,57 +2,114 @@ package gui
 
 import (
 	"fmt"
-	"sort"
-	"strconv"
 	"strings"
 
+	"groupie-tracker/geo"
 	"groupie-tracker/models"
+	"groupie-tracker/recherche"
+	"groupie-tracker/texte"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
 // filters.go - les filtres pour la page d'accueil
-// on a des sliders pour les dates et des checkboxes pour les membres / locations
+// on a des range sliders pour les dates et des checkboxes pour les membres / locations
 
 // Filtres - contient les valeurs actuelles des filtres
+// les tags JSON servent pour les presets sauvegardes
 type Filtres struct {
-	CreationMin int
-	CreationMax int
-	AlbumMin    int
-	AlbumMax    int
-	NbMembres   map[int]bool    // les nombres de membres coches
-	Locations   map[string]bool // les locations cochees
+	CreationMin int             `json:"creationMin"`
+	CreationMax int             `json:"creationMax"`
+	AlbumMin    int             `json:"albumMin"`
+	AlbumMax    int             `json:"albumMax"`
+	NbMembres   map[int]bool    `json:"nbMembres,omitempty"`   // les nombres de membres coches
+	Locations   map[string]bool `json:"locations,omitempty"`   // les cles canoniques des villes cochees (voir geo.CleLieu)
+	Collections map[string]bool `json:"collections,omitempty"` // les collections cochees (par nom)
+	Tags        map[string]bool `json:"tags,omitempty"`        // les tags coches (normalises, voir texte.Normaliser)
 }
 
-// NewFiltres - cree des filtres par defaut (tout est ouvert)
-func NewFiltres() *Filtres {
-	return &Filtres{
-		CreationMin: 1950,
-		CreationMax: 2025,
-		AlbumMin:    1950,
-		AlbumMax:    2025,
-		NbMembres:   make(map[int]bool),
-		Locations:   make(map[string]bool),
-	}
+// BornesFiltres - les min/max de chaque filtre numerique, calcules depuis les artistes charges
+// avec l'histogramme des annees pour les range sliders (index 0 = l'annee min)
+type BornesFiltres struct {
+	CreationMin   int
+	CreationMax   int
+	AlbumMin      int
+	AlbumMax      int
+	MembresMin    int
+	MembresMax    int
+	HistoCreation []int
+	HistoAlbum    []int
 }
 
-// extraireAnneePremierAlbum - parse l'annee depuis le format "DD-MM-YYYY" du premier album
-func extraireAnneePremierAlbum(dateStr string) int {
-	parts := strings.Split(dateStr, "-")
-	if len(parts) == 3 {
-		annee, err := strconv.Atoi(parts[2])
-		if err == nil {
-			return annee
+// calculerBornes - parcourt les artistes une fois au demarrage pour trouver les bornes
+// comme ca plus d'artistes exclus parce qu'ils sont avant 1950 ou apres 2025
+func calculerBornes(artistes []models.Artiste) BornesFiltres {
+	var b BornesFiltres
+	premierCreation, premierAlbum, premierMembres := true, true, true
+
+	for _, artiste := range artistes {
+		if premierCreation || artiste.DateCreation < b.CreationMin {
+			b.CreationMin = artiste.DateCreation
+		}
+		if premierCreation || artiste.DateCreation > b.CreationMax {
+			b.CreationMax = artiste.DateCreation
+		}
+		premierCreation = false
+
+		if annee := recherche.AnneeAlbum(artiste.PremierAlbum); annee > 0 {
+			if premierAlbum || annee < b.AlbumMin {
+				b.AlbumMin = annee
+			}
+			if premierAlbum || annee > b.AlbumMax {
+				b.AlbumMax = annee
+			}
+			premierAlbum = false
+		}
+
+		nb := len(artiste.Membres)
+		if premierMembres || nb < b.MembresMin {
+			b.MembresMin = nb
+		}
+		if premierMembres || nb > b.MembresMax {
+			b.MembresMax = nb
+		}
+		premierMembres = false
+	}
+
+	// les histogrammes, une case par annee entre min et max
+	b.HistoCreation = make([]int, b.CreationMax-b.CreationMin+1)
+	b.HistoAlbum = make([]int, b.AlbumMax-b.AlbumMin+1)
+	for _, artiste := range artistes {
+		b.HistoCreation[artiste.DateCreation-b.CreationMin]++
+		if annee := recherche.AnneeAlbum(artiste.PremierAlbum); annee > 0 {
+			b.HistoAlbum[annee-b.AlbumMin]++
 		}
 	}
-	return 0
+
+	return b
+}
+
+// NewFiltres - cree des filtres par defaut (tout est ouvert entre les bornes)
+func NewFiltres(bornes BornesFiltres) *Filtres {
+	return &Filtres{
+		CreationMin: bornes.CreationMin,
+		CreationMax: bornes.CreationMax,
+		AlbumMin:    bornes.AlbumMin,
+		AlbumMax:    bornes.AlbumMax,
+		NbMembres:   make(map[int]bool),
+		Locations:   make(map[string]bool),
+		Collections: make(map[string]bool),
+		Tags:        make(map[string]bool),
+	}
 }
 
 // appliquerFiltres - filtre les artistes selon les criteres choisis
-func appliquerFiltres(artistes []models.Artiste, filtres *Filtres, locData models.IndexLocations) []models.Artiste {
+// etiquettes donne les collections et les tags d'un artiste (nil si on filtre pas dessus)
+func appliquerFiltres(artistes []models.Artiste, filtres *Filtres, locData models.IndexLocations, etiquettes func(id int) (collections, tags []string)) []models.Artiste {
 	var resultat []models.Artiste
 
 	for _, artiste := range artistes {
@@ -62,47 +119,27 @@ func appliquerFiltres(artistes []models.Artiste, filtres *Filtres, locData model
 		}
 
 		// filtre par annee du premier album
-		anneeAlbum := extraireAnneePremierAlbum(artiste.PremierAlbum)
+		anneeAlbum := recherche.AnneeAlbum(artiste.PremierAlbum)
 		if anneeAlbum > 0 && (anneeAlbum < filtres.AlbumMin || anneeAlbum > filtres.AlbumMax) {
 			continue
 		}
 
 		// filtre par nombre de membres (si des checkboxes sont cochees)
 		if len(filtres.NbMembres) > 0 {
-			nbMembres := len(artiste.Membres)
-			// on check si le nb de membres correspond, 7 = "7 ou plus"
-			trouve := false
-			for nb := range filtres.NbMembres {
-				if nb == 7 && nbMembres >= 7 {
-					trouve = true
-					break
-				}
-				if nb == nbMembres {
-					trouve = true
-					break
-				}
-			}
-			if !trouve {
+			if !filtres.NbMembres[len(artiste.Membres)] {
 				continue
 			}
 		}
 
 		// filtre par location (si des locations sont cochees)
+		// on compare les cles canoniques exactement, plus de faux positifs genre "georgia" dans "georgia-usa"
 		if len(filtres.Locations) > 0 {
 			trouveLoc := false
 			for _, loc := range locData.Index {
 				if loc.ID == artiste.ID {
 					for _, lieu := range loc.Locations {
-						lieuPropre := strings.ReplaceAll(lieu, "_", " ")
-						lieuPropre = strings.ReplaceAll(lieuPropre, "-", ", ")
-						// on check si la location correspond
-						for locFiltre := range filtres.Locations {
-							if strings.Contains(strings.ToLower(lieuPropre), strings.ToLower(locFiltre)) {
-								trouveLoc = true
-								break
-							}
-						}
-						if trouveLoc {
+						if filtres.Locations[geo.CleLieu(lieu)] {
+							trouveLoc = true
 							break
 						}
 					}
@@ -114,171 +151,182 @@ func appliquerFiltres(artistes []models.Artiste, filtres *Filtres, locData model
 			}
 		}
 
+		// filtre par collection et par tag (il suffit d'en avoir un des coches, comme pour les lieux)
+		if etiquettes != nil && (len(filtres.Collections) > 0 || len(filtres.Tags) > 0) {
+			collections, tags := etiquettes(artiste.ID)
+			if len(filtres.Collections) > 0 && !contientUn(collections, filtres.Collections, strings.TrimSpace) {
+				continue
+			}
+			if len(filtres.Tags) > 0 && !contientUn(tags, filtres.Tags, texte.Normaliser) {
+				continue
+			}
+		}
+
 		resultat = append(resultat, artiste)
 	}
 
 	return resultat
 }
 
-// extraireTousLesPays - extrait tous les pays uniques depuis les locations
-func extraireTousLesPays(locData models.IndexLocations) []string {
-	paysMap := make(map[string]bool)
-	for _, loc := range locData.Index {
-		for _, lieu := range loc.Locations {
-			parts := strings.Split(lieu, "-")
-			if len(parts) >= 2 {
-				pays := parts[len(parts)-1]
-				pays = strings.ReplaceAll(pays, "_", " ")
-				paysMap[pays] = true
-			}
+// contientUn - au moins une des valeurs (passee par cle) est cochee
+func contientUn(valeurs []string, coches map[string]bool, cle func(string) string) bool {
+	for _, v := range valeurs {
+		if coches[cle(v)] {
+			return true
 		}
 	}
-
-	var pays []string
-	for p := range paysMap {
-		pays = append(pays, p)
-	}
-	sort.Strings(pays)
-	return pays
+	return false
 }
 
 // creerPanneauFiltres - cree le panneau lateral avec tous les filtres
-func creerPanneauFiltres(filtres *Filtres, locData models.IndexLocations, onFiltreChange func()) fyne.CanvasObject {
+// les bornes viennent de calculerBornes, rien n'est code en dur
+// les widgets ne touchent jamais les filtres directement: ils passent par l'etat,
+// et ils ecoutent l'etat pour se remettre a jour (reset, undo, redo...)
+// collections et tags c'est ce que l'utilisateur a cree, la liste est prise a la construction du panneau
+func creerPanneauFiltres(etat *EtatFiltres, bornes BornesFiltres, locData models.IndexLocations, collections, tags []string) fyne.CanvasObject {
 	// === FILTRE DATE DE CREATION (range slider) ===
-	labelCreation := widget.NewLabel(fmt.Sprintf("Création: %d - %d", filtres.CreationMin, filtres.CreationMax))
+	labelCreation := widget.NewLabel("")
 	labelCreation.TextStyle = fyne.TextStyle{Bold: true}
 
-	sliderCreationMin := widget.NewSlider(1950, 2025)
-	sliderCreationMin.Value = float64(filtres.CreationMin)
-	sliderCreationMin.Step = 1
-
-	sliderCreationMax := widget.NewSlider(1950, 2025)
-	sliderCreationMax.Value = float64(filtres.CreationMax)
-	sliderCreationMax.Step = 1
+	sliderCreation := NewRangeSlider(bornes.CreationMin, bornes.CreationMax, bornes.HistoCreation, func(bas, haut int) {
+		etat.Modifier("creation", func(f *Filtres) {
+			f.CreationMin, f.CreationMax = bas, haut
+		})
+	})
 
-	sliderCreationMin.OnChanged = func(val float64) {
-		filtres.CreationMin = int(val)
-		if filtres.CreationMin > filtres.CreationMax {
-			filtres.CreationMax = filtres.CreationMin
-			sliderCreationMax.Value = val
-			sliderCreationMax.Refresh()
-		}
-		labelCreation.SetText(fmt.Sprintf("Création: %d - %d", filtres.CreationMin, filtres.CreationMax))
-		onFiltreChange()
-	}
-	sliderCreationMax.OnChanged = func(val float64) {
-		filtres.CreationMax = int(val)
-		if filtres.CreationMax < filtres.CreationMin {
-			filtres.CreationMin = filtres.CreationMax
-			sliderCreationMin.Value = val
-			sliderCreationMin.Refresh()
-		}
-		labelCreation.SetText(fmt.Sprintf("Création: %d - %d", filtres.CreationMin, filtres.CreationMax))
-		onFiltreChange()
-	}
+	sliderCreation.OnChangeEnded = etat.FinirModif
 
-	filtreCreation := container.NewVBox(
-		labelCreation,
-		widget.NewLabel("Min:"),
-		sliderCreationMin,
-		widget.NewLabel("Max:"),
-		sliderCreationMax,
-	)
+	filtreCreation := container.NewVBox(labelCreation, sliderCreation)
 
 	// === FILTRE PREMIER ALBUM (range slider) ===
-	labelAlbum := widget.NewLabel(fmt.Sprintf("1er Album: %d - %d", filtres.AlbumMin, filtres.AlbumMax))
+	labelAlbum := widget.NewLabel("")
 	labelAlbum.TextStyle = fyne.TextStyle{Bold: true}
 
-	sliderAlbumMin := widget.NewSlider(1950, 2025)
-	sliderAlbumMin.Value = float64(filtres.AlbumMin)
-	sliderAlbumMin.Step = 1
+	sliderAlbum := NewRangeSlider(bornes.AlbumMin, bornes.AlbumMax, bornes.HistoAlbum, func(bas, haut int) {
+		etat.Modifier("album", func(f *Filtres) {
+			f.AlbumMin, f.AlbumMax = bas, haut
+		})
+	})
 
-	sliderAlbumMax := widget.NewSlider(1950, 2025)
-	sliderAlbumMax.Value = float64(filtres.AlbumMax)
-	sliderAlbumMax.Step = 1
+	sliderAlbum.OnChangeEnded = etat.FinirModif
 
-	sliderAlbumMin.OnChanged = func(val float64) {
-		filtres.AlbumMin = int(val)
-		if filtres.AlbumMin > filtres.AlbumMax {
-			filtres.AlbumMax = filtres.AlbumMin
-			sliderAlbumMax.Value = val
-			sliderAlbumMax.Refresh()
-		}
-		labelAlbum.SetText(fmt.Sprintf("1er Album: %d - %d", filtres.AlbumMin, filtres.AlbumMax))
-		onFiltreChange()
-	}
-	sliderAlbumMax.OnChanged = func(val float64) {
-		filtres.AlbumMax = int(val)
-		if filtres.AlbumMax < filtres.AlbumMin {
-			filtres.AlbumMin = filtres.AlbumMax
-			sliderAlbumMin.Value = val
-			sliderAlbumMin.Refresh()
-		}
-		labelAlbum.SetText(fmt.Sprintf("1er Album: %d - %d", filtres.AlbumMin, filtres.AlbumMax))
-		onFiltreChange()
-	}
-
-	filtreAlbum := container.NewVBox(
-		labelAlbum,
-		widget.NewLabel("Min:"),
-		sliderAlbumMin,
-		widget.NewLabel("Max:"),
-		sliderAlbumMax,
-	)
+	filtreAlbum := container.NewVBox(labelAlbum, sliderAlbum)
 
 	// === FILTRE NOMBRE DE MEMBRES (checkboxes) ===
 	labelMembres := widget.NewLabel("Nombre de membres:")
 	labelMembres.TextStyle = fyne.TextStyle{Bold: true}
 
 	membresChecks := container.NewVBox(labelMembres)
-	for i := 1; i <= 7; i++ {
+	checksMembres := make(map[int]*widget.Check)
+	for i := bornes.MembresMin; i <= bornes.MembresMax; i++ {
 		nb := i
-		label := fmt.Sprintf("%d", nb)
-		if nb == 7 {
-			label = "7+"
-		}
-		check := widget.NewCheck(label, func(checked bool) {
-			if checked {
-				filtres.NbMembres[nb] = true
-			} else {
-				delete(filtres.NbMembres, nb)
-			}
-			onFiltreChange()
+		check := widget.NewCheck(fmt.Sprintf("%d", nb), func(checked bool) {
+			etat.Modifier("", func(f *Filtres) {
+				if checked {
+					f.NbMembres[nb] = true
+				} else {
+					delete(f.NbMembres, nb)
+				}
+			})
 		})
+		checksMembres[nb] = check
 		membresChecks.Add(check)
 	}
 
-	// === FILTRE LOCATIONS (checkboxes des pays) ===
-	labelLocations := widget.NewLabel("Pays:")
+	// === FILTRE LOCATIONS (arbre continent -> pays -> region -> ville) ===
+	labelLocations := widget.NewLabel("Lieux:")
 	labelLocations.TextStyle = fyne.TextStyle{Bold: true}
 
-	paysListe := extraireTousLesPays(locData)
-	locChecks := container.NewVBox(labelLocations)
-	for _, pays := range paysListe {
-		p := pays // capture
-		check := widget.NewCheck(p, func(checked bool) {
-			if checked {
-				filtres.Locations[p] = true
-			} else {
-				delete(filtres.Locations, p)
-			}
-			onFiltreChange()
+	arbreLieux := creerFiltreLieux(etat, geo.ConstruireHierarchie(locData))
+	locChecks := container.NewVBox(labelLocations, arbreLieux)
+
+	// === FILTRE COLLECTIONS ET TAGS (checkboxes) ===
+	labelCollections := widget.NewLabel("Collections:")
+	labelCollections.TextStyle = fyne.TextStyle{Bold: true}
+	collectionsChecks := container.NewVBox(labelCollections)
+	checksCollections := make(map[string]*widget.Check)
+	for _, nom := range collections {
+		nomCollection := nom
+		check := widget.NewCheck("📁 "+nomCollection, func(checked bool) {
+			etat.Modifier("", func(f *Filtres) {
+				if checked {
+					f.Collections[nomCollection] = true
+				} else {
+					delete(f.Collections, nomCollection)
+				}
+			})
 		})
-		locChecks.Add(check)
+		checksCollections[nomCollection] = check
+		collectionsChecks.Add(check)
 	}
 
-	// bouton reset pour tout remettre a zero
-	btnReset := widget.NewButton("🔄 Reset filtres", func() {
-		filtres.CreationMin = 1950
-		filtres.CreationMax = 2025
-		filtres.AlbumMin = 1950
-		filtres.AlbumMax = 2025
-		filtres.NbMembres = make(map[int]bool)
-		filtres.Locations = make(map[string]bool)
-		onFiltreChange()
-	})
+	labelTags := widget.NewLabel("Tags:")
+	labelTags.TextStyle = fyne.TextStyle{Bold: true}
+	tagsChecks := container.NewVBox(labelTags)
+	checksTags := make(map[string]*widget.Check)
+	for _, t := range tags {
+		cle := texte.Normaliser(t)
+		check := widget.NewCheck("#"+t, func(checked bool) {
+			etat.Modifier("", func(f *Filtres) {
+				if checked {
+					f.Tags[cle] = true
+				} else {
+					delete(f.Tags, cle)
+				}
+			})
+		})
+		checksTags[cle] = check
+		tagsChecks.Add(check)
+	}
+
+	// boutons reset / annuler / refaire
+	btnReset := widget.NewButton("🔄 Reset filtres", etat.Reset)
 	btnReset.Importance = widget.HighImportance
 
+	btnAnnuler := widget.NewButtonWithIcon("Annuler", theme.ContentUndoIcon(), etat.Annuler)
+	btnRefaire := widget.NewButtonWithIcon("Refaire", theme.ContentRedoIcon(), etat.Refaire)
+
+	// quand l'etat change on remet tous les widgets d'accord avec lui
+	etat.AjouterEcouteur(func() {
+		f := etat.Filtres()
+
+		labelCreation.SetText(fmt.Sprintf("Création: %d - %d", f.CreationMin, f.CreationMax))
+		sliderCreation.SetValeurs(f.CreationMin, f.CreationMax)
+		labelAlbum.SetText(fmt.Sprintf("1er Album: %d - %d", f.AlbumMin, f.AlbumMax))
+		sliderAlbum.SetValeurs(f.AlbumMin, f.AlbumMax)
+
+		// on touche directement Checked pour pas redeclencher OnChanged
+		for nb, check := range checksMembres {
+			if check.Checked != f.NbMembres[nb] {
+				check.Checked = f.NbMembres[nb]
+				check.Refresh()
+			}
+		}
+		for nom, check := range checksCollections {
+			if check.Checked != f.Collections[nom] {
+				check.Checked = f.Collections[nom]
+				check.Refresh()
+			}
+		}
+		for cle, check := range checksTags {
+			if check.Checked != f.Tags[cle] {
+				check.Checked = f.Tags[cle]
+				check.Refresh()
+			}
+		}
+
+		if etat.PeutAnnuler() {
+			btnAnnuler.Enable()
+		} else {
+			btnAnnuler.Disable()
+		}
+		if etat.PeutRefaire() {
+			btnRefaire.Enable()
+		} else {
+			btnRefaire.Disable()
+		}
+	})
+
 	// on met tout dans un container scrollable
 	contenuFiltres := container.NewVBox(
 		widget.NewSeparator(),
@@ -292,9 +340,19 @@ func creerPanneauFiltres(filtres *Filtres, locData models.IndexLocations, onFilt
 		widget.NewSeparator(),
 		locChecks,
 		widget.NewSeparator(),
-		btnReset,
-		layout.NewSpacer(),
 	)
+	// les collections et les tags seulement si l'utilisateur en a cree
+	if len(collections) > 0 {
+		contenuFiltres.Add(collectionsChecks)
+		contenuFiltres.Add(widget.NewSeparator())
+	}
+	if len(tags) > 0 {
+		contenuFiltres.Add(tagsChecks)
+		contenuFiltres.Add(widget.NewSeparator())
+	}
+	contenuFiltres.Add(container.NewGridWithColumns(2, btnAnnuler, btnRefaire))
+	contenuFiltres.Add(btnReset)
+	contenuFiltres.Add(layout.NewSpacer())
 
 	scrollFiltres := container.NewVScroll(contenuFiltres)
 	scrollFiltres.SetMinSize(fyne.NewSize(250, 400))
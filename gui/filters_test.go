@@ -0,0 +1,190 @@
+package gui
+
+import (
+	"strconv"
+	"testing"
+
+	"groupie-tracker/geo"
+	"groupie-tracker/models"
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// filters_test.go - le panneau des filtres et EtatFiltres doivent toujours etre d'accord:
+// bouger un widget change l'etat, et changer l'etat (reset, undo, redo) remet les widgets a jour
+
+// panneauTest - le panneau des filtres dans une fenetre de test, avec ses sliders et ses checkboxes
+type panneauTest struct {
+	etat     *EtatFiltres
+	bornes   BornesFiltres
+	creation *RangeSlider
+	album    *RangeSlider
+	fenetre  fyne.Window
+}
+
+func nouveauPanneauTest(t *testing.T) *panneauTest {
+	t.Helper()
+	test.NewTempApp(t)
+
+	artistes := []models.Artiste{
+		{ID: 1, Nom: "Queen", Membres: []string{"Freddie Mercury", "Brian May"}, DateCreation: 1970, PremierAlbum: "14-12-1973"},
+		{ID: 2, Nom: "Pink Floyd", Membres: []string{"Roger Waters"}, DateCreation: 1965, PremierAlbum: "05-08-1967"},
+		{ID: 3, Nom: "Beyoncé", Membres: []string{"Beyoncé Knowles", "Kelly", "Michelle"}, DateCreation: 1997, PremierAlbum: "24-06-2003"},
+	}
+	locData := models.IndexLocations{Index: []models.LocationData{{ID: 1, Locations: []string{"london-uk"}}}}
+	bornes := calculerBornes(artistes)
+	etat := NewEtatFiltres(bornes)
+
+	fenetre := test.NewWindow(creerPanneauFiltres(etat, bornes, locData, []string{"Rock"}, []string{"live"}))
+	fenetre.Resize(fyne.NewSize(400, 2000))
+	t.Cleanup(fenetre.Close)
+
+	p := &panneauTest{etat: etat, bornes: bornes, fenetre: fenetre}
+	for _, o := range test.LaidOutObjects(fenetre.Content()) {
+		if r, ok := o.(*RangeSlider); ok {
+			// le premier c'est la creation, le deuxieme le premier album
+			if p.creation == nil {
+				p.creation = r
+			} else {
+				p.album = r
+			}
+		}
+	}
+	if p.creation == nil || p.album == nil {
+		t.Fatal("les deux range sliders sont pas dans le panneau")
+	}
+	return p
+}
+
+// check - la checkbox qui affiche ce texte
+// on la recherche a chaque fois: l'arbre des lieux recycle ses checkboxes quand il se redessine
+func (p *panneauTest) check(t *testing.T, texteCheck string) *widget.Check {
+	t.Helper()
+	for _, o := range test.LaidOutObjects(p.fenetre.Content()) {
+		if c, ok := o.(*widget.Check); ok && c.Text == texteCheck {
+			return c
+		}
+	}
+	t.Fatalf("pas de checkbox %q dans le panneau", texteCheck)
+	return nil
+}
+
+// glisser - un drag complet de la poignee la plus proche de x vers x
+func glisser(r *RangeSlider, x float32) {
+	r.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(x, 0)}})
+	r.DragEnd()
+}
+
+func TestPanneauFiltresModifieEtat(t *testing.T) {
+	p := nouveauPanneauTest(t)
+
+	// la poignee du haut de la creation descend
+	glisser(p.creation, p.creation.positionDe(1990))
+	if f := p.etat.Filtres(); f.CreationMax != 1990 || f.CreationMin != p.bornes.CreationMin {
+		t.Errorf("creation = %d - %d, attendu %d - 1990", f.CreationMin, f.CreationMax, p.bornes.CreationMin)
+	}
+
+	// la poignee du bas de l'album monte
+	glisser(p.album, p.album.positionDe(1970))
+	if f := p.etat.Filtres(); f.AlbumMin != 1970 || f.AlbumMax != p.bornes.AlbumMax {
+		t.Errorf("album = %d - %d, attendu 1970 - %d", f.AlbumMin, f.AlbumMax, p.bornes.AlbumMax)
+	}
+
+	for nb := p.bornes.MembresMin; nb <= p.bornes.MembresMax; nb++ {
+		test.Tap(p.check(t, strconv.Itoa(nb)))
+		if !p.etat.Filtres().NbMembres[nb] {
+			t.Errorf("la checkbox %d membres coche pas le filtre", nb)
+		}
+	}
+
+	test.Tap(p.check(t, "📁 Rock"))
+	if !p.etat.Filtres().Collections["Rock"] {
+		t.Error("la checkbox de la collection coche pas le filtre")
+	}
+	test.Tap(p.check(t, "#live"))
+	if !p.etat.Filtres().Tags[texte.Normaliser("live")] {
+		t.Error("la checkbox du tag coche pas le filtre")
+	}
+
+	// le continent dans l'arbre des lieux coche toutes ses villes
+	test.Tap(p.check(t, "Europe (1)"))
+	if !p.etat.Filtres().Locations[geo.CleLieu("london-uk")] {
+		t.Error("la checkbox du continent coche pas ses villes")
+	}
+
+	// et decocher enleve
+	test.Tap(p.check(t, "#live"))
+	if p.etat.Filtres().Tags[texte.Normaliser("live")] {
+		t.Error("decocher le tag l'enleve pas du filtre")
+	}
+}
+
+func TestPanneauFiltresSuitEtat(t *testing.T) {
+	p := nouveauPanneauTest(t)
+
+	f := *NewFiltres(p.bornes)
+	f.CreationMin, f.CreationMax = 1966, 1990
+	f.AlbumMin, f.AlbumMax = 1968, 2000
+	f.NbMembres[2] = true
+	f.Collections["Rock"] = true
+	f.Tags[texte.Normaliser("live")] = true
+	f.Locations[geo.CleLieu("london-uk")] = true
+
+	// verifier - les widgets montrent exactement ces filtres
+	verifier := func(etape string, f Filtres) {
+		t.Helper()
+		if p.creation.Bas != f.CreationMin || p.creation.Haut != f.CreationMax {
+			t.Errorf("%s: slider creation = %d - %d, attendu %d - %d", etape, p.creation.Bas, p.creation.Haut, f.CreationMin, f.CreationMax)
+		}
+		if p.album.Bas != f.AlbumMin || p.album.Haut != f.AlbumMax {
+			t.Errorf("%s: slider album = %d - %d, attendu %d - %d", etape, p.album.Bas, p.album.Haut, f.AlbumMin, f.AlbumMax)
+		}
+		if got := p.check(t, "2").Checked; got != f.NbMembres[2] {
+			t.Errorf("%s: checkbox 2 membres = %v", etape, got)
+		}
+		if got := p.check(t, "📁 Rock").Checked; got != f.Collections["Rock"] {
+			t.Errorf("%s: checkbox collection = %v", etape, got)
+		}
+		if got := p.check(t, "#live").Checked; got != f.Tags[texte.Normaliser("live")] {
+			t.Errorf("%s: checkbox tag = %v", etape, got)
+		}
+		if got := p.check(t, "Europe (1)").Checked; got != f.Locations[geo.CleLieu("london-uk")] {
+			t.Errorf("%s: checkbox continent = %v", etape, got)
+		}
+	}
+
+	defaut := p.etat.Filtres()
+	p.etat.Remplacer(f)
+	verifier("Remplacer", f)
+	p.etat.Annuler()
+	verifier("Annuler", defaut)
+	p.etat.Refaire()
+	verifier("Refaire", f)
+	p.etat.Reset()
+	verifier("Reset", defaut)
+}
+
+func TestPanneauFiltresDeuxDragsDeuxEtapes(t *testing.T) {
+	p := nouveauPanneauTest(t)
+
+	// un drag qui passe par plusieurs valeurs = une seule etape
+	p.creation.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(p.creation.positionDe(1990), 0)}})
+	glisser(p.creation, p.creation.positionDe(1985))
+	// un deuxieme drag du meme slider = une autre etape
+	glisser(p.creation, p.creation.positionDe(1980))
+
+	p.etat.Annuler()
+	if f := p.etat.Filtres(); f.CreationMax != 1985 {
+		t.Errorf("apres un Annuler la creation max = %d, attendu 1985 (juste le deuxieme drag annule)", f.CreationMax)
+	}
+	p.etat.Annuler()
+	if f := p.etat.Filtres(); f.CreationMax != p.bornes.CreationMax {
+		t.Errorf("apres deux Annuler la creation max = %d, attendu %d", f.CreationMax, p.bornes.CreationMax)
+	}
+	if p.etat.PeutAnnuler() {
+		t.Error("il reste des etapes a annuler apres les deux drags")
+	}
+}
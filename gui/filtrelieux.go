@@ -0,0 +1,141 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"groupie-tracker/geo"
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// filtrelieux.go - le filtre par lieu sous forme d'arbre continent -> pays -> region -> ville
+// chaque noeud a une checkbox a 3 etats: cochee, pas cochee, ou partielle
+// (quand seulement une partie des villes en dessous sont cochees)
+
+// creerFiltreLieux - cree l'arbre des lieux avec sa barre de recherche
+// l'arbre ecoute l'etat des filtres pour se redessiner (reset, undo...)
+func creerFiltreLieux(etat *EtatFiltres, hier *geo.HierarchieLieux) fyne.CanvasObject {
+	// la copie des filtres courants, mise a jour par l'ecouteur plus bas
+	filtres := etat.Filtres()
+
+	// les noeuds a afficher quand on cherche une ville, nil = on affiche tout
+	var visibles map[string]bool
+	var arbre *widget.Tree
+
+	// compter - combien de villes sont cochees sous un noeud, et combien y'en a en tout
+	compter := func(id string) (coches, total int) {
+		for _, cle := range hier.Villes(id) {
+			total++
+			if filtres.Locations[cle] {
+				coches++
+			}
+		}
+		return coches, total
+	}
+
+	arbre = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			var enfants []string
+			if id == "" {
+				enfants = hier.Racines
+			} else if n, ok := hier.Noeuds[id]; ok {
+				enfants = n.Enfants
+			}
+			if visibles == nil {
+				return enfants
+			}
+			var resultat []string
+			for _, e := range enfants {
+				if visibles[e] {
+					resultat = append(resultat, e)
+				}
+			}
+			return resultat
+		},
+		func(id widget.TreeNodeID) bool {
+			n, ok := hier.Noeuds[id]
+			return id == "" || (ok && n.Niveau != geo.NiveauVille)
+		},
+		func(branche bool) fyne.CanvasObject {
+			return widget.NewCheck("", nil)
+		},
+		func(id widget.TreeNodeID, branche bool, obj fyne.CanvasObject) {
+			check := obj.(*widget.Check)
+			n, ok := hier.Noeuds[id]
+			if !ok {
+				return
+			}
+
+			// on coupe le callback le temps de mettre a jour l'etat sinon ca boucle
+			check.OnChanged = nil
+			coches, total := compter(id)
+			check.Checked = total > 0 && coches == total
+			check.Partial = coches > 0 && coches < total
+			if branche {
+				check.Text = fmt.Sprintf("%s (%d)", majusculesMots(n.Nom), total)
+			} else {
+				check.Text = majusculesMots(n.Nom)
+			}
+			check.Refresh()
+
+			// cocher un noeud coche toutes les villes en dessous (un noeud partiel passe a coche)
+			check.OnChanged = func(coche bool) {
+				etat.Modifier("", func(f *Filtres) {
+					for _, cle := range hier.Villes(id) {
+						if coche {
+							f.Locations[cle] = true
+						} else {
+							delete(f.Locations, cle)
+						}
+					}
+				})
+			}
+		},
+	)
+
+	// la recherche pour trouver une ville vite fait sans tout deplier a la main
+	recherche := widget.NewEntry()
+	recherche.SetPlaceHolder("🔍 Chercher un lieu...")
+	recherche.OnChanged = func(saisie string) {
+		saisie = texte.Normaliser(saisie)
+		if saisie == "" {
+			visibles = nil
+			arbre.CloseAllBranches()
+			arbre.Refresh()
+			return
+		}
+
+		// un noeud qui matche est visible avec tous ses parents et tout ce qu'il y a en dessous
+		visibles = make(map[string]bool)
+		for id, n := range hier.Noeuds {
+			if !strings.Contains(texte.Normaliser(n.Nom), saisie) {
+				continue
+			}
+			parties := strings.Split(id, "/")
+			for i := range parties {
+				visibles[strings.Join(parties[:i+1], "/")] = true
+			}
+			for autre := range hier.Noeuds {
+				if strings.HasPrefix(autre, id+"/") {
+					visibles[autre] = true
+				}
+			}
+		}
+		arbre.OpenAllBranches()
+		arbre.Refresh()
+	}
+
+	// l'arbre a besoin d'une taille fixe sinon il s'ecrase dans la VBox
+	zoneArbre := container.NewGridWrap(fyne.NewSize(230, 320), arbre)
+
+	etat.AjouterEcouteur(func() {
+		filtres = etat.Filtres()
+		arbre.Refresh()
+	})
+
+	return container.NewVBox(recherche, zoneArbre)
+}
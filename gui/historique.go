@@ -0,0 +1,149 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"groupie-tracker/models"
+	"groupie-tracker/recherche"
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// historique.go - se souvient des dernieres recherches et des derniers artistes consultes
+// c'est sauve dans les preferences, on les retrouve au prochain lancement
+// quand la barre de recherche est vide on les propose comme suggestions
+
+// les cles dans les preferences Fyne
+const (
+	clePrefRecherches = "historique_recherches"
+	clePrefRecents    = "artistes_recents"
+)
+
+// on en garde pas plus que ca, sinon la liste devient inutile
+const (
+	maxRecherchesRecentes = 10
+	maxArtistesRecents    = 12
+)
+
+// les types de suggestion en plus de ceux de l'index
+const (
+	typeRechercheRecente = "recent search"
+	typeArtisteRecent    = "recent artist"
+)
+
+// recherchesRecentes - les dernieres recherches, la plus recente en premier
+func (a *AppGroupie) recherchesRecentes() []string {
+	var recherches []string
+	chargerPrefJSON(a.app.Preferences(), clePrefRecherches, &recherches)
+	return recherches
+}
+
+// ajouterRecherche - note une recherche (si elle est valide), elle remonte en tete si on l'avait deja
+func (a *AppGroupie) ajouterRecherche(requete string) {
+	requete = strings.TrimSpace(requete)
+	if n, err := recherche.Analyser(requete); err != nil || n == nil {
+		return
+	}
+
+	recherches := []string{requete}
+	for _, r := range a.recherchesRecentes() {
+		// "Queen" et "queen" c'est la meme recherche
+		if texte.Normaliser(r) != texte.Normaliser(requete) {
+			recherches = append(recherches, r)
+		}
+	}
+	if len(recherches) > maxRecherchesRecentes {
+		recherches = recherches[:maxRecherchesRecentes]
+	}
+	sauverPrefJSON(a.app.Preferences(), clePrefRecherches, recherches)
+}
+
+// artistesRecents - les derniers artistes consultes, le plus recent en premier
+// (ceux qui existent plus dans l'API sont ignores)
+func (a *AppGroupie) artistesRecents() []models.Artiste {
+	var ids []int
+	chargerPrefJSON(a.app.Preferences(), clePrefRecents, &ids)
+
+	parID := make(map[int]models.Artiste, len(a.artistes))
+	for _, art := range a.artistes {
+		parID[art.ID] = art
+	}
+	var recents []models.Artiste
+	for _, id := range ids {
+		if art, ok := parID[id]; ok {
+			recents = append(recents, art)
+		}
+	}
+	return recents
+}
+
+// ajouterRecent - note qu'on vient d'ouvrir la page d'un artiste
+func (a *AppGroupie) ajouterRecent(id int) {
+	var anciens []int
+	chargerPrefJSON(a.app.Preferences(), clePrefRecents, &anciens)
+
+	ids := []int{id}
+	for _, ancien := range anciens {
+		if ancien != id {
+			ids = append(ids, ancien)
+		}
+	}
+	if len(ids) > maxArtistesRecents {
+		ids = ids[:maxArtistesRecents]
+	}
+	sauverPrefJSON(a.app.Preferences(), clePrefRecents, ids)
+}
+
+// suggestionsHistorique - ce qu'on propose quand la barre est vide:
+// les recherches recentes puis les artistes consultes
+func (a *AppGroupie) suggestionsHistorique() []models.SuggestionRecherche {
+	var suggestions []models.SuggestionRecherche
+	for _, r := range a.recherchesRecentes() {
+		suggestions = append(suggestions, models.SuggestionRecherche{Texte: r, Type: typeRechercheRecente})
+	}
+	for _, art := range a.artistesRecents() {
+		suggestions = append(suggestions, models.SuggestionRecherche{Texte: art.Nom, Type: typeArtisteRecent, ArtisteID: art.ID})
+	}
+	return suggestions
+}
+
+// creerBandeRecents - la bande "Récemment consultés" de la page d'accueil
+// un bouton par artiste avec sa photo en icone, renvoie nil si on a encore rien consulte
+// les photos arrivent en arriere plan (l'accueil attend pas le reseau), abandonnees avec ctx
+func (a *AppGroupie) creerBandeRecents(ctx context.Context) fyne.CanvasObject {
+	recents := a.artistesRecents()
+	if len(recents) == 0 {
+		return nil
+	}
+
+	boutons := container.NewHBox()
+	for _, art := range recents {
+		artiste := art // capture pour la closure
+		btn := widget.NewButtonWithIcon(artiste.Nom, theme.MediaPhotoIcon(), func() {
+			a.ouvrirArtiste(artiste)
+		})
+		btn.Importance = widget.LowImportance
+		boutons.Add(btn)
+
+		// la miniature suffit largement pour une icone
+		go func() {
+			data, err := a.chargerMiniatureArtiste(ctx, artiste)
+			if err != nil || ctx.Err() != nil {
+				return // on garde l'icone d'attente
+			}
+			fyne.Do(func() {
+				btn.SetIcon(fyne.NewStaticResource(fmt.Sprintf("artist_%d_recent", artiste.ID), data))
+			})
+		}()
+	}
+
+	titre := widget.NewLabel("👁 Récemment consultés")
+	titre.TextStyle = fyne.TextStyle{Bold: true}
+	return container.NewBorder(nil, nil, titre, nil, container.NewHScroll(boutons))
+}
@@ -1,15 +1,17 @@
 package gui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"time"
 
+	"groupie-tracker/index"
 	"groupie-tracker/models"
+	"groupie-tracker/recherche"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -18,203 +20,246 @@ import (
 
 // creerPageAccueil - construit toute la page d'accueil
 // avec la recherche en haut, les filtres a gauche et la grille au centre
-func (a *AppGroupie) creerPageAccueil() fyne.CanvasObject {
-	// le header avec le titre
+// etat c'est ce qu'on avait en quittant l'accueil (recherche, filtres, scroll), vide pour un accueil neuf
+func (a *AppGroupie) creerPageAccueil(etat EtatPage) fyne.CanvasObject {
+	// le header avec les boutons precedent / suivant et le titre
 	header := creerHeader()
+	header.Objects = append([]fyne.CanvasObject{a.creerBoutonsNavigation()}, header.Objects...)
+	btnFavoris := widget.NewButton("⭐ Favoris", a.afficherFavoris)
+	btnFavoris.Importance = widget.LowImportance
+	header.Add(btnFavoris)
+	btnNotes := widget.NewButton("📝 Notes", a.afficherNotes)
+	btnNotes.Importance = widget.LowImportance
+	header.Add(btnNotes)
+	a.btnComparaison = widget.NewButton("", a.afficherComparaison)
+	a.btnComparaison.Importance = widget.LowImportance
+	a.majBoutonComparaison()
+	header.Add(a.btnComparaison)
+
+	// l'etat des filtres (bindable, avec undo/redo), repris de la derniere fois si on revient
+	etatFiltres := NewEtatFiltres(a.bornes)
+	if etat.Filtres != nil {
+		etatFiltres.Remplacer(*etat.Filtres)
+	}
 
-	// les filtres
-	filtres := NewFiltres()
-
-	// la grille des artistes (on la cree d'abord vide)
-	grille := container.NewGridWrap(fyne.NewSize(220, 380))
-
-	// container pour les suggestions
-	containerSuggestions := container.NewVBox()
+	// les images des cards se chargent dans ce contexte, annule quand on quitte la page
+	if a.annulerAccueil != nil {
+		a.annulerAccueil()
+	}
+	ctxPage, annulerPage := context.WithCancel(context.Background())
+	a.annulerAccueil = annulerPage
+
+	// ce que la grille affiche en ce moment
+	var artistesAffiches []models.Artiste
+	raisonsAffichees := make(map[int]raisonCorrespondance)
+
+	// la grille des artistes, virtualisee: seules les cards visibles existent,
+	// et elles sont reutilisees quand on scrolle
+	grille := widget.NewGridWrap(
+		func() int {
+			return len(artistesAffiches)
+		},
+		func() fyne.CanvasObject {
+			return a.newCarteArtiste(ctxPage)
+		},
+		func(id widget.GridWrapItemID, objet fyne.CanvasObject) {
+			if id >= len(artistesAffiches) {
+				return
+			}
+			art := artistesAffiches[id]
+			var raison *raisonCorrespondance
+			if r, ok := raisonsAffichees[art.ID]; ok {
+				raison = &r
+			}
+			objet.(*carteArtiste).afficher(art, raison)
+		},
+	)
 
 	// la barre de recherche
 	entryRecherche := NewEntryRecherche()
 	a.barreRecherche = entryRecherche
 
+	// quand on choisit une suggestion (clic ou Entree), on va sur l'artiste
+	// sauf pour une recherche de l'historique, qu'on remet dans la barre,
+	// et pour un membre ou un lieu, qui ont leur propre page
+	entryRecherche.OnSelection = func(s models.SuggestionRecherche) {
+		switch {
+		case s.Type == typeRechercheRecente:
+			entryRecherche.SetText(s.Texte)
+		case s.Type == index.TypeMembre && s.Cle != "":
+			a.afficherMembre(s.Cle)
+		case s.Type == index.TypeLieu && s.Cle != "":
+			a.afficherLieu(s.Cle)
+		default:
+			if art, ok := a.artisteParID(s.ArtisteID); ok {
+				a.ouvrirArtiste(art)
+			}
+		}
+	}
+
 	// variable pour le texte de recherche actuel
-	texteRecherche := ""
+	// (mis avant de brancher OnChanged, sinon on ouvrirait les suggestions en revenant)
+	texteRecherche := etat.Recherche
+	entryRecherche.SetText(etat.Recherche)
 
-	// fonction pour rafraichir la grille avec les filtres et la recherche
-	rafraichirGrille := func() {
-		// on applique d'abord les filtres
-		artistesFiltres := appliquerFiltres(a.artistes, filtres, a.locationsData)
-
-		// puis la recherche textuelle si y'a un texte
-		if texteRecherche != "" {
-			var artistesRecherche []models.Artiste
-			texteMin := strings.ToLower(texteRecherche)
-			for _, art := range artistesFiltres {
-				// on check dans le nom
-				if strings.Contains(strings.ToLower(art.Nom), texteMin) {
-					artistesRecherche = append(artistesRecherche, art)
-					continue
-				}
-				// on check dans les membres
-				trouveMembre := false
-				for _, m := range art.Membres {
-					if strings.Contains(strings.ToLower(m), texteMin) {
-						trouveMembre = true
-						break
-					}
-				}
-				if trouveMembre {
-					artistesRecherche = append(artistesRecherche, art)
-					continue
-				}
-				// on check la date de creation
-				if strings.Contains(fmt.Sprintf("%d", art.DateCreation), texteMin) {
-					artistesRecherche = append(artistesRecherche, art)
-					continue
-				}
-				// on check le premier album
-				if strings.Contains(strings.ToLower(art.PremierAlbum), texteMin) {
-					artistesRecherche = append(artistesRecherche, art)
-				}
+	// le message d'erreur quand la requete est mal ecrite (cache si tout va bien)
+	labelErreur := widget.NewLabel("")
+	labelErreur.TextStyle = fyne.TextStyle{Monospace: true}
+	labelErreur.Importance = widget.DangerImportance
+	labelErreur.Hide()
+
+	// label nb de resultats
+	labelResultats := widget.NewLabel(fmt.Sprintf("%d artistes", len(a.artistes)))
+
+	// le scroll a remettre quand la grille aura ses artistes
+	defilement := etat.Defilement
+
+	// afficherResultat - met a jour l'erreur et la grille avec ce que le calcul a prepare
+	afficherResultat := func(res resultatRecherche, requete string) {
+		if res.erreur != nil {
+			// on montre ou ca coince
+			var errReq *recherche.ErreurRequete
+			if errors.As(res.erreur, &errReq) {
+				labelErreur.SetText(errReq.Souligner(requete) + "\n⚠️ " + errReq.Message)
+			} else {
+				labelErreur.SetText("⚠️ " + res.erreur.Error())
 			}
-			artistesFiltres = artistesRecherche
+			labelErreur.Show()
+		} else {
+			labelErreur.Hide()
 		}
 
-		// on reconstruit la grille
-		grille.RemoveAll()
-		for _, artiste := range artistesFiltres {
-			art := artiste // capture pour la closure
-			card := a.creerCardArtiste(art)
-			grille.Add(card)
-		}
+		// la grille se redessine toute seule a partir de la liste
+		artistesAffiches = res.artistes
+		raisonsAffichees = res.raisons
 		grille.Refresh()
+		if defilement > 0 {
+			// le premier resultat en revenant sur la page: on remet le scroll ou il etait
+			grille.ScrollToOffset(defilement)
+			defilement = 0
+		} else {
+			grille.ScrollToTop()
+		}
+		labelResultats.SetText(fmt.Sprintf("%d artistes", len(res.artistes)))
+	}
+
+	// le calcul tourne en arriere plan, une nouvelle frappe annule celui d'avant
+	// (si on revient sur l'accueil, l'ancienne page arrete de calculer pour rien)
+	if a.tacheRecherche != nil {
+		a.tacheRecherche.Annuler()
+	}
+	tache := &tacheAnnulable{}
+	a.tacheRecherche = tache
+
+	// des suggestions ont ete demandees par une frappe et pas encore affichees
+	// (un changement de filtre entre temps annule le calcul, il doit les refaire)
+	suggestionsEnAttente := false
+
+	// le tri de la grille (sauve dans les preferences)
+	tri := a.chargerTri()
+
+	// fonction pour rafraichir la grille avec les filtres et la recherche
+	rafraichir := func(delai time.Duration) {
+		filtres := etatFiltres.Filtres()
+		requete := texteRecherche
+		triActuel := tri
+		avecSuggestions := suggestionsEnAttente
+		tache.Lancer(delai, func(ctx context.Context) func() {
+			res, ok := a.calculerRecherche(ctx, filtres, requete, triActuel, avecSuggestions)
+			if !ok {
+				return nil
+			}
+			return func() {
+				if avecSuggestions {
+					suggestionsEnAttente = false
+					entryRecherche.AfficherSuggestions(res.suggestions)
+				}
+				afficherResultat(res, requete)
+			}
+		})
+	}
+	rafraichirGrille := func() {
+		rafraichir(0)
 	}
 
 	// callback quand on tape dans la recherche
+	// on attend que la frappe se calme avant de calculer (voir rechercheasync.go)
 	entryRecherche.OnChanged = func(texte string) {
 		texteRecherche = texte
 
-		// generer les suggestions
-		containerSuggestions.RemoveAll()
-		if texte != "" {
-			suggestions := genererSuggestions(texte, a.artistes, a.locationsData)
-			panneau := creerPanneauSuggestions(suggestions, func(s models.SuggestionRecherche) {
-				// quand on clique sur une suggestion, on va sur l'artiste
-				for _, art := range a.artistes {
-					if art.ID == s.ArtisteID {
-						a.afficherDetail(art)
-						return
-					}
-				}
-			})
-			containerSuggestions.Add(panneau)
+		// les suggestions arrivent avec le resultat, dans la liste sous la barre
+		// (barre vide = l'historique, ca c'est immediat)
+		suggestionsEnAttente = texte != ""
+		if texte == "" {
+			entryRecherche.AfficherSuggestions(a.suggestionsHistorique())
 		}
-		containerSuggestions.Refresh()
 
-		rafraichirGrille()
+		rafraichir(delaiRecherche)
+	}
+
+	// l'historique s'affiche aussi quand on arrive dans la barre vide
+	entryRecherche.OnFocusVide = func() {
+		entryRecherche.AfficherSuggestions(a.suggestionsHistorique())
+	}
+
+	// Entree sans suggestion ouverte: on garde quand meme la recherche dans l'historique
+	entryRecherche.OnSubmitted = func(texte string) {
+		a.ajouterRecherche(texte)
 	}
 
 	// stocker le callback de rafraichissement
 	a.onRefreshAccueil = rafraichirGrille
 
-	// callback quand les filtres changent
-	onFiltreChange := func() {
-		rafraichirGrille()
+	// en quittant la page on note ou on en est, pour la retrouver pareil avec "precedent"
+	a.capturerPage = func(e *EtatPage) {
+		e.Recherche = entryRecherche.Text
+		filtres := etatFiltres.Filtres()
+		e.Filtres = &filtres
+		e.Defilement = grille.GetScrollOffset()
 	}
 
 	// construire le panneau de filtres
-	panneauFiltres := creerPanneauFiltres(filtres, a.locationsData, onFiltreChange)
+	panneauFiltres := creerPanneauFiltres(etatFiltres, a.bornes, a.locationsData, a.nomsCollections(), a.tousLesTags())
 
-	// afficher la grille initiale
-	rafraichirGrille()
+	// les presets au dessus des filtres
+	barrePresets := a.creerBarrePresets(etatFiltres, entryRecherche)
+	zoneGauche := container.NewBorder(barrePresets, nil, nil, nil, panneauFiltres)
 
-	// label nb de resultats
-	labelResultats := widget.NewLabel(fmt.Sprintf("%d artistes", len(a.artistes)))
+	// la grille se rafraichit toute seule a chaque changement des filtres
+	// (l'ecouteur est aussi appele une premiere fois, ca affiche la grille initiale)
+	etatFiltres.AjouterEcouteur(rafraichirGrille)
 
 	// layout de la recherche
 	barreRechercheContainer := container.NewVBox(
 		container.NewBorder(nil, nil, nil, nil, entryRecherche),
-		containerSuggestions,
+		labelErreur,
 	)
 
-	// la zone principale avec la grille scrollable
-	scrollGrille := container.NewVScroll(grille)
-	scrollGrille.SetMinSize(fyne.NewSize(600, 500))
-
 	// assemble tout: header en haut, filtres a gauche, grille au centre
 	zoneHaut := container.NewVBox(
 		header,
 		widget.NewSeparator(),
 		barreRechercheContainer,
-		labelResultats,
-		widget.NewSeparator(),
-	)
-
-	contenu := container.NewBorder(
-		zoneHaut,       // haut
-		nil,            // bas
-		panneauFiltres, // gauche
-		nil,            // droite
-		scrollGrille,   // centre
 	)
-
-	return contenu
-}
-
-// creerCardArtiste - cree une card pour un artiste dans la grille
-// avec son image, son nom et l'annee de creation
-func (a *AppGroupie) creerCardArtiste(artiste models.Artiste) fyne.CanvasObject {
-	// on charge l'image en arriere plan
-	var imgWidget *canvas.Image
-
-	imageData := a.getImageArtiste(artiste)
-	if imageData != nil {
-		imgRes := fyne.NewStaticResource(fmt.Sprintf("artist_%d", artiste.ID), imageData)
-		imgWidget = canvas.NewImageFromResource(imgRes)
-		imgWidget.FillMode = canvas.ImageFillContain
-		imgWidget.SetMinSize(fyne.NewSize(150, 150))
-	} else {
-		// image par defaut si on arrive pas a charger
-		imgWidget = canvas.NewImageFromResource(nil)
-		imgWidget.SetMinSize(fyne.NewSize(150, 150))
+	if bandeRecents := a.creerBandeRecents(ctxPage); bandeRecents != nil {
+		zoneHaut.Add(bandeRecents)
 	}
-
-	// le nom de l'artiste
-	labelNom := widget.NewLabel(artiste.Nom)
-	labelNom.TextStyle = fyne.TextStyle{Bold: true}
-	labelNom.Alignment = fyne.TextAlignCenter
-	labelNom.Wrapping = fyne.TextWrapWord
-
-	// l'annee de creation
-	labelAnnee := widget.NewLabel(fmt.Sprintf("📅 %d", artiste.DateCreation))
-	labelAnnee.Alignment = fyne.TextAlignCenter
-
-	// etoile favori
-	etoileTxt := "☆"
-	if a.estFavori(artiste.ID) {
-		etoileTxt = "⭐"
-	}
-	btnFavori := widget.NewButton(etoileTxt, func() {
-		a.toggleFavori(artiste.ID)
-		// on rafraichit l'accueil pour mettre a jour les etoiles
-		if a.onRefreshAccueil != nil {
-			a.afficherAccueil()
-		}
+	// le tri a droite du nombre de resultats
+	barreTri := a.creerBarreTri(func(nouveau Tri) {
+		tri = nouveau
+		rafraichirGrille()
 	})
-	btnFavori.Importance = widget.LowImportance
+	zoneHaut.Add(container.NewBorder(nil, nil, labelResultats, barreTri))
+	zoneHaut.Add(widget.NewSeparator())
 
-	// bouton pour voir le detail
-	btnDetail := widget.NewButton("Voir détails", func() {
-		a.afficherDetail(artiste)
-	})
-	btnDetail.Importance = widget.MediumImportance
-
-	// on assemble le tout dans un container vertical
-	cardContent := container.NewVBox(
-		imgWidget,
-		labelNom,
-		labelAnnee,
-		container.NewHBox(layout.NewSpacer(), btnFavori, layout.NewSpacer()),
-		btnDetail,
+	contenu := container.NewBorder(
+		zoneHaut,   // haut
+		nil,        // bas
+		zoneGauche, // gauche
+		nil,        // droite
+		grille,     // centre
 	)
 
-	return widget.NewCard("", "", cardContent)
+	// les suggestions de la barre s'affichent dans un calque par dessus tout le reste
+	return container.NewStack(contenu, entryRecherche.Calque())
 }
@@ -0,0 +1,324 @@
+package gui
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"groupie-tracker/recherche"
+	"groupie-tracker/texte"
+)
+
+// liens.go - les liens groupie:// pour ouvrir l'app direct sur un artiste ou une recherche
+// on les passe en argument au lancement (groupie-tracker groupie://artist/42), comme ca
+// on peut mettre des liens vers des artistes dans nos docs et rouvrir exactement la meme vue
+//
+//	groupie://artist/42                    l'artiste 42 (ou groupie://artist/queen, par le nom)
+//	groupie://search?q=queen&country=uk    une recherche, les autres parametres deviennent des champs
+//	groupie://search?filtre.creation=1965-1980&filtre.membres=4
+//	                                       les filtres du panneau de gauche (filtre.creation, filtre.album,
+//	                                       filtre.membres, filtre.lieu, filtre.collection, filtre.tag)
+//	groupie://member/Phil%20Collins        la page d'un membre
+//	groupie://location/london-uk           la page d'un lieu
+//	groupie://favorites, groupie://notes   les pages des favoris et des notes
+//	groupie://compare/1,2,queen            la comparaison de 2 a 4 artistes (IDs ou noms)
+//
+// sans le groupie:// les arguments sont pris comme une recherche (groupie-tracker queen)
+
+// schemaLien - le debut des liens
+const schemaLien = "groupie"
+
+// parserArguments - les arguments de la ligne de commande en page a ouvrir
+// ok est faux si y'a rien a ouvrir (pas d'arguments)
+func (a *AppGroupie) parserArguments(args []string) (etat EtatPage, ok bool, err error) {
+	if len(args) == 0 {
+		return EtatPage{}, false, nil
+	}
+	if len(args) == 1 && strings.HasPrefix(args[0], schemaLien+":") {
+		etat, err = a.parserLien(args[0])
+		return etat, err == nil, err
+	}
+	return EtatPage{Page: pageAccueil, Recherche: strings.Join(args, " ")}, true, nil
+}
+
+// parserLien - transforme un lien groupie:// en etat de page
+func (a *AppGroupie) parserLien(lien string) (EtatPage, error) {
+	u, err := url.Parse(lien)
+	if err != nil {
+		return EtatPage{}, fmt.Errorf("lien illisible: %w", err)
+	}
+	if u.Scheme != schemaLien {
+		return EtatPage{}, fmt.Errorf("le lien doit commencer par %s://", schemaLien)
+	}
+
+	// groupie://artist/42 -> Host "artist", Path "/42"
+	chemin := strings.Trim(u.Host+u.Path, "/")
+	page, reste, _ := strings.Cut(chemin, "/")
+
+	switch strings.ToLower(page) {
+	case "artist", "artiste":
+		if reste == "" {
+			return EtatPage{}, fmt.Errorf("il manque l'artiste dans %s", lien)
+		}
+		id, err := a.artisteDuLien(reste)
+		if err != nil {
+			return EtatPage{}, err
+		}
+		return EtatPage{Page: pageArtiste, ArtisteID: id}, nil
+
+	case "compare", "comparer", "comparaison":
+		var ids []int
+		for _, morceau := range strings.Split(reste, ",") {
+			if morceau = strings.TrimSpace(morceau); morceau == "" {
+				continue
+			}
+			id, err := a.artisteDuLien(morceau)
+			if err != nil {
+				return EtatPage{}, err
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) < minComparaison || len(ids) > maxComparaison {
+			return EtatPage{}, fmt.Errorf("il faut de %d a %d artistes a comparer dans %s", minComparaison, maxComparaison, lien)
+		}
+		return EtatPage{Page: pageComparaison, Artistes: ids}, nil
+
+	case "member", "membre":
+		membre, ok := a.membres.Membre(reste)
+		if !ok {
+			return EtatPage{}, fmt.Errorf("pas de membre \"%s\"", reste)
+		}
+		return EtatPage{Page: pageMembre, Cle: membre.Nom}, nil
+
+	case "location", "lieu":
+		if reste == "" {
+			return EtatPage{}, fmt.Errorf("il manque le lieu dans %s", lien)
+		}
+		return EtatPage{Page: pageLieu, Cle: reste}, nil
+
+	case "favorites", "favoris":
+		return EtatPage{Page: pageFavoris}, nil
+
+	case "notes":
+		return EtatPage{Page: pageNotes}, nil
+
+	case "search", "recherche", "":
+		params := u.Query()
+		filtres, err := a.filtresDepuisParametres(params)
+		if err != nil {
+			return EtatPage{}, err
+		}
+		return EtatPage{Page: pageAccueil, Recherche: requeteDepuisParametres(params), Filtres: filtres}, nil
+	}
+	return EtatPage{}, fmt.Errorf("page inconnue \"%s\" (artist, search, member, location, favorites, notes, compare)", page)
+}
+
+// artisteDuLien - l'artiste d'un lien, par son ID ou par son nom
+func (a *AppGroupie) artisteDuLien(reste string) (int, error) {
+	if id, err := strconv.Atoi(reste); err == nil {
+		if _, ok := a.artisteParID(id); !ok {
+			return 0, fmt.Errorf("pas d'artiste %d", id)
+		}
+		return id, nil
+	}
+	for _, art := range a.artistes {
+		if texte.Normaliser(art.Nom) == texte.Normaliser(reste) {
+			return art.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("pas d'artiste \"%s\"", reste)
+}
+
+// prefixeFiltre - les parametres des filtres, pour pas les confondre avec les champs de la recherche
+const prefixeFiltre = "filtre."
+
+// filtresDepuisParametres - sort les parametres filtre.* du lien (ils sont enleves de params)
+// nil si le lien a pas de filtres; ce qui manque reste a la valeur par defaut, et les
+// intervalles sont ramenes dans les bornes des donnees (comme pour un preset importe)
+func (a *AppGroupie) filtresDepuisParametres(params url.Values) (*Filtres, error) {
+	f := NewFiltres(a.bornes)
+	trouve := false
+
+	for cle, valeurs := range params {
+		nom, ok := strings.CutPrefix(cle, prefixeFiltre)
+		if !ok {
+			continue
+		}
+		delete(params, cle)
+		trouve = true
+
+		for _, valeur := range valeurs {
+			var err error
+			switch nom {
+			case "creation":
+				f.CreationMin, f.CreationMax, err = intervalleDuLien(valeur)
+			case "album":
+				f.AlbumMin, f.AlbumMax, err = intervalleDuLien(valeur)
+			case "membres":
+				var nb int
+				if nb, err = strconv.Atoi(valeur); err == nil {
+					f.NbMembres[nb] = true
+				}
+			case "lieu":
+				f.Locations[valeur] = true
+			case "collection":
+				f.Collections[valeur] = true
+			case "tag":
+				f.Tags[texte.Normaliser(valeur)] = true
+			default:
+				return nil, fmt.Errorf("filtre inconnu \"%s\" (creation, album, membres, lieu, collection, tag)", nom)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("filtre %s=%s invalide", cle, valeur)
+			}
+		}
+	}
+	if !trouve {
+		return nil, nil
+	}
+
+	p, _ := validerPreset(PresetRecherche{Filtres: *f}, a.bornes)
+	return &p.Filtres, nil
+}
+
+// intervalleDuLien - "1965-1980" -> 1965, 1980
+func intervalleDuLien(valeur string) (int, int, error) {
+	debut, fin, ok := strings.Cut(valeur, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("intervalle sans tiret: %s", valeur)
+	}
+	bas, err := strconv.Atoi(strings.TrimSpace(debut))
+	if err != nil {
+		return 0, 0, err
+	}
+	haut, err := strconv.Atoi(strings.TrimSpace(fin))
+	return bas, haut, err
+}
+
+// parametresFiltres - l'inverse de filtresDepuisParametres, seulement ce qui change des
+// filtres par defaut (un lien sans filtres actifs reste court)
+func (a *AppGroupie) parametresFiltres(f *Filtres, params url.Values) {
+	if f == nil {
+		return
+	}
+	if f.CreationMin != a.bornes.CreationMin || f.CreationMax != a.bornes.CreationMax {
+		params.Set(prefixeFiltre+"creation", fmt.Sprintf("%d-%d", f.CreationMin, f.CreationMax))
+	}
+	if f.AlbumMin != a.bornes.AlbumMin || f.AlbumMax != a.bornes.AlbumMax {
+		params.Set(prefixeFiltre+"album", fmt.Sprintf("%d-%d", f.AlbumMin, f.AlbumMax))
+	}
+
+	// les maps ont pas d'ordre, on trie pour qu'un meme etat donne toujours le meme lien
+	var membres []int
+	for nb, coche := range f.NbMembres {
+		if coche {
+			membres = append(membres, nb)
+		}
+	}
+	sort.Ints(membres)
+	for _, nb := range membres {
+		params.Add(prefixeFiltre+"membres", strconv.Itoa(nb))
+	}
+	for nom, coches := range map[string]map[string]bool{"lieu": f.Locations, "collection": f.Collections, "tag": f.Tags} {
+		var valeurs []string
+		for v, coche := range coches {
+			if coche {
+				valeurs = append(valeurs, v)
+			}
+		}
+		sort.Strings(valeurs)
+		for _, v := range valeurs {
+			params.Add(prefixeFiltre+nom, v)
+		}
+	}
+}
+
+// requeteDepuisParametres - q=queen&country=uk -> "queen country:uk"
+// chaque parametre autre que q devient un champ de la recherche avancee
+// (concerts=>10 donne concerts>10, une valeur avec des espaces ou des guillemets est citee, voir recherche.Citer)
+func requeteDepuisParametres(params url.Values) string {
+	var morceaux []string
+	if q := strings.TrimSpace(params.Get("q")); q != "" {
+		morceaux = append(morceaux, q)
+	}
+
+	// l'ordre des parametres est perdu par url.Values, on trie pour avoir toujours la meme requete
+	champs := make([]string, 0, len(params))
+	for champ := range params {
+		if champ != "q" {
+			champs = append(champs, champ)
+		}
+	}
+	sort.Strings(champs)
+
+	for _, champ := range champs {
+		for _, valeur := range params[champ] {
+			valeur = strings.TrimSpace(valeur)
+			if valeur == "" {
+				continue
+			}
+			op := ":"
+			for _, o := range []string{">=", "<=", ">", "<", "="} {
+				if reste, ok := strings.CutPrefix(valeur, o); ok {
+					op, valeur = o, reste
+					break
+				}
+			}
+			morceaux = append(morceaux, champ+op+recherche.Citer(valeur))
+		}
+	}
+	return strings.Join(morceaux, " ")
+}
+
+// lienPage - le lien groupie:// qui rouvre cette page (l'inverse de parserLien)
+func (a *AppGroupie) lienPage(etat EtatPage) string {
+	u := url.URL{Scheme: schemaLien}
+	switch etat.Page {
+	case pageArtiste:
+		u.Host, u.Path = "artist", "/"+strconv.Itoa(etat.ArtisteID)
+	case pageMembre:
+		u.Host, u.Path = "member", "/"+etat.Cle
+	case pageLieu:
+		u.Host, u.Path = "location", "/"+etat.Cle
+	case pageFavoris:
+		u.Host = "favorites"
+	case pageNotes:
+		u.Host = "notes"
+	case pageComparaison:
+		ids := make([]string, len(etat.Artistes))
+		for i, id := range etat.Artistes {
+			ids[i] = strconv.Itoa(id)
+		}
+		u.Host, u.Path = "compare", "/"+strings.Join(ids, ",")
+	default:
+		u.Host = "search"
+		params := url.Values{}
+		if etat.Recherche != "" {
+			params.Set("q", etat.Recherche)
+		}
+		a.parametresFiltres(etat.Filtres, params)
+		u.RawQuery = params.Encode()
+	}
+	return u.String()
+}
+
+// copierLienPage - met le lien de la page affichee dans le presse-papier
+// (celle de l'onglet affiche s'il y a des onglets)
+func (a *AppGroupie) copierLienPage() {
+	if o := a.ongletAffiche(); o != nil {
+		a.copierLien(a.lienPage(EtatPage{Page: pageArtiste, ArtisteID: o.artiste.ID}))
+		return
+	}
+	if len(a.navigation.pages) == 0 {
+		return
+	}
+	a.memoriserPage()
+	a.copierLien(a.lienPage(a.navigation.pages[a.navigation.position]))
+}
+
+// copierLien - met un lien dans le presse-papier
+func (a *AppGroupie) copierLien(lien string) {
+	a.app.Clipboard().SetContent(lien)
+}
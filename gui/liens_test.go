@@ -0,0 +1,139 @@
+package gui
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"groupie-tracker/geo"
+	"groupie-tracker/index"
+	"groupie-tracker/models"
+	"groupie-tracker/recherche"
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// liens_test.go - un lien copie avec Ctrl+L doit rouvrir exactement la meme vue:
+// lienPage puis parserLien redonne la page de depart, filtres compris
+
+func nouvelleAppLiens(t *testing.T) *AppGroupie {
+	t.Helper()
+	artistes := []models.Artiste{
+		{ID: 1, Nom: "Queen", Membres: []string{"Freddie Mercury", "Brian May"}, DateCreation: 1970, PremierAlbum: "14-12-1973"},
+		{ID: 2, Nom: "Pink Floyd", Membres: []string{"Roger Waters"}, DateCreation: 1965, PremierAlbum: "05-08-1967"},
+		{ID: 3, Nom: "Beyoncé", Membres: []string{"Beyoncé Knowles", "Kelly", "Michelle"}, DateCreation: 1997, PremierAlbum: "24-06-2003"},
+	}
+	return &AppGroupie{
+		app:      test.NewTempApp(t),
+		artistes: artistes,
+		bornes:   calculerBornes(artistes),
+		membres:  index.ConstruireMembres(artistes),
+	}
+}
+
+func TestLienAllerRetour(t *testing.T) {
+	a := nouvelleAppLiens(t)
+
+	filtres := NewFiltres(a.bornes)
+	filtres.CreationMin, filtres.CreationMax = 1966, 1990
+	filtres.NbMembres[2] = true
+	filtres.NbMembres[3] = true
+	filtres.Locations[geo.CleLieu("london-uk")] = true
+	filtres.Collections["Rock & Roll"] = true
+	filtres.Tags[texte.Normaliser("live")] = true
+
+	pages := []EtatPage{
+		{Page: pageArtiste, ArtisteID: 2},
+		{Page: pageMembre, Cle: "Brian May"},
+		{Page: pageLieu, Cle: "london-uk"},
+		{Page: pageFavoris},
+		{Page: pageNotes},
+		{Page: pageComparaison, Artistes: []int{1, 3}},
+		{Page: pageAccueil, Recherche: `queen member:"Brian May"`},
+		{Page: pageAccueil, Recherche: "queen", Filtres: filtres},
+		{Page: pageAccueil, Filtres: filtres},
+	}
+	for _, depart := range pages {
+		lien := a.lienPage(depart)
+		retour, err := a.parserLien(lien)
+		if err != nil {
+			t.Errorf("%s: %v", lien, err)
+			continue
+		}
+		if !reflect.DeepEqual(retour, depart) {
+			t.Errorf("%s:\n  parti de %+v\n  revenu a %+v", lien, depart, retour)
+		}
+	}
+}
+
+func TestLienFiltresParDefautPasDansLeLien(t *testing.T) {
+	a := nouvelleAppLiens(t)
+
+	lien := a.lienPage(EtatPage{Page: pageAccueil, Recherche: "queen", Filtres: NewFiltres(a.bornes)})
+	if lien != "groupie://search?q=queen" {
+		t.Errorf("lien = %s, attendu groupie://search?q=queen", lien)
+	}
+}
+
+func TestLienFiltres(t *testing.T) {
+	a := nouvelleAppLiens(t)
+
+	etat, err := a.parserLien("groupie://search?q=queen&country=uk&filtre.creation=1900-1980&filtre.membres=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// les filtres deviennent pas des champs de la recherche
+	if etat.Recherche != "queen country:uk" {
+		t.Errorf("recherche = %q, attendu %q", etat.Recherche, "queen country:uk")
+	}
+	if etat.Filtres == nil {
+		t.Fatal("les filtres du lien sont perdus")
+	}
+	// l'intervalle est ramene dans les bornes, ce qui manque reste par defaut
+	if f := etat.Filtres; f.CreationMin != a.bornes.CreationMin || f.CreationMax != 1980 {
+		t.Errorf("creation = %d - %d, attendu %d - 1980", f.CreationMin, f.CreationMax, a.bornes.CreationMin)
+	}
+	if f := etat.Filtres; f.AlbumMin != a.bornes.AlbumMin || f.AlbumMax != a.bornes.AlbumMax || !f.NbMembres[2] {
+		t.Errorf("filtres = %+v", f)
+	}
+
+	for _, lien := range []string{
+		"groupie://search?filtre.creation=1970",
+		"groupie://search?filtre.membres=deux",
+		"groupie://search?filtre.couleur=rouge",
+	} {
+		if _, err := a.parserLien(lien); err == nil {
+			t.Errorf("%s: attendu une erreur", lien)
+		}
+	}
+}
+
+func TestLienValeursCitees(t *testing.T) {
+	a := nouvelleAppLiens(t)
+
+	// un parametre avec des guillemets, un antislash ou des parentheses reste une seule valeur
+	for _, membre := range []string{`Freddie "Mercury"`, `C:\Queen`, "Brian (May)", `a\"b`} {
+		lien := "groupie://search?" + url.Values{"member": {membre}}.Encode()
+		etat, err := a.parserLien(lien)
+		if err != nil {
+			t.Errorf("%s: %v", lien, err)
+			continue
+		}
+		p, err := recherche.Compiler(etat.Recherche)
+		if err != nil {
+			t.Errorf("%s: la requete %s se parse pas: %v", lien, etat.Recherche, err)
+			continue
+		}
+		avec := recherche.Fiche{Artiste: models.Artiste{Membres: []string{membre}}}
+		sans := recherche.Fiche{Artiste: models.Artiste{Membres: []string{"Roger Taylor"}}}
+		if !p(avec) || p(sans) {
+			t.Errorf("%s: la requete %s cherche pas exactement %q", lien, etat.Recherche, membre)
+		}
+
+		// et le lien de la page redonne la meme requete
+		if retour, err := a.parserLien(a.lienPage(etat)); err != nil || retour.Recherche != etat.Recherche {
+			t.Errorf("%s: retour %q %v, attendu %q", lien, retour.Recherche, err, etat.Recherche)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"groupie-tracker/geo"
+	"groupie-tracker/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// lieu.go - la page d'un lieu: tous les artistes qui y ont joue, avec leurs dates
+// on y arrive par une suggestion "location", un point de la carte ou la liste des concerts
+
+// concertsArtiste - les dates d'un artiste dans un lieu
+type concertsArtiste struct {
+	Artiste models.Artiste
+	Dates   []string // triees de la plus ancienne a la plus recente
+}
+
+// parserDate - parse une date de l'API "DD-MM-YYYY" (avec ou sans l'etoile des concerts)
+func parserDate(d string) time.Time {
+	t, _ := time.Parse("02-01-2006", strings.TrimPrefix(d, "*"))
+	return t
+}
+
+// concertsAuLieu - tous les concerts d'un lieu, depuis la relation de tous les artistes
+// les artistes sont ranges par date de leur premier concert la-bas
+func (a *AppGroupie) concertsAuLieu(lieu string) []concertsArtiste {
+	cle := geo.CleLieu(lieu)
+	var resultat []concertsArtiste
+	for _, rel := range a.relationsData.Index {
+		var dates []string
+		for l, ds := range rel.DatesLocations {
+			if geo.CleLieu(l) == cle {
+				for _, d := range ds {
+					dates = append(dates, strings.TrimPrefix(d, "*"))
+				}
+			}
+		}
+		if len(dates) == 0 {
+			continue
+		}
+		art, ok := a.artisteParID(rel.ID)
+		if !ok {
+			continue
+		}
+		sort.SliceStable(dates, func(i, j int) bool {
+			return parserDate(dates[i]).Before(parserDate(dates[j]))
+		})
+		resultat = append(resultat, concertsArtiste{Artiste: art, Dates: dates})
+	}
+	sort.SliceStable(resultat, func(i, j int) bool {
+		return parserDate(resultat[i].Dates[0]).Before(parserDate(resultat[j].Dates[0]))
+	})
+	return resultat
+}
+
+// creerPageLieu - construit la page d'un lieu
+// ctx est annule quand on quitte la page (la carte arrete de charger)
+func (a *AppGroupie) creerPageLieu(ctx context.Context, lieu string) fyne.CanvasObject {
+	boutonsNav := a.creerBoutonsNavigation()
+
+	titre := widget.NewLabel("📍 " + formaterLieu(lieu))
+	titre.TextStyle = fyne.TextStyle{Bold: true}
+	header := container.NewHBox(boutonsNav, titre, layout.NewSpacer())
+
+	concerts := a.concertsAuLieu(lieu)
+	nbConcerts := 0
+	for _, c := range concerts {
+		nbConcerts += len(c.Dates)
+	}
+	labelResume := widget.NewLabel(fmt.Sprintf("🎤 %d artistes  —  🎵 %d concerts", len(concerts), nbConcerts))
+
+	// la liste: l'artiste (cliquable) et ses dates la-bas
+	liste := container.NewVBox()
+	if len(concerts) == 0 {
+		liste.Add(widget.NewLabel("  Aucun concert trouvé"))
+	}
+	for _, c := range concerts {
+		artiste := c.Artiste // capture pour la closure
+		btnArtiste := widget.NewButton(artiste.Nom, func() {
+			a.ouvrirArtiste(artiste)
+		})
+		btnArtiste.Importance = widget.LowImportance
+		btnArtiste.Alignment = widget.ButtonAlignLeading
+
+		labelDates := widget.NewLabel("📅 " + strings.Join(c.Dates, ", "))
+		labelDates.Wrapping = fyne.TextWrapWord
+		liste.Add(container.NewBorder(nil, nil, btnArtiste, nil, labelDates))
+	}
+
+	// une petite carte centree sur le lieu, le geocoding se fait en arriere-plan
+	carteContainer := container.NewVBox(widget.NewLabel("🗺️ Chargement de la carte..."))
+	go func() {
+		coords, err := geo.GeocoderLieuAPI(ctx, lieu)
+		fyne.Do(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			carteContainer.RemoveAll()
+			if err != nil {
+				carteContainer.Add(widget.NewLabel("🗺️ Lieu pas géolocalisé"))
+			} else {
+				point := PointCarte{Lieu: geo.NettoyerLieu(lieu), Cle: lieu, Coords: coords}
+				carteContainer.Add(dessinerCarteVue([]PointCarte{point}, vueAutour(coords, 30, 15), 320, 160, nil))
+			}
+			carteContainer.Refresh()
+		})
+	}()
+
+	contenu := container.NewVBox(
+		header,
+		widget.NewSeparator(),
+		labelResume,
+		container.NewHBox(carteContainer),
+		widget.NewSeparator(),
+		liste,
+	)
+	return container.NewVScroll(contenu)
+}
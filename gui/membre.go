@@ -0,0 +1,77 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"groupie-tracker/index"
+	"groupie-tracker/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// membre.go - la page d'un membre avec tous les groupes ou il a joue
+// on y arrive en cliquant un membre sur la page d'un artiste ou par une suggestion "member"
+
+// creerPageMembre - construit la page d'un membre
+// ctx est annule quand on quitte la page (les images des cards arretent de charger)
+func (a *AppGroupie) creerPageMembre(ctx context.Context, membre index.Membre) fyne.CanvasObject {
+	boutonsNav := a.creerBoutonsNavigation()
+
+	titre := widget.NewLabel("🎤 " + membre.Nom)
+	titre.TextStyle = fyne.TextStyle{Bold: true}
+	header := container.NewHBox(boutonsNav, titre, layout.NewSpacer())
+
+	texteGroupes := "Membre d'un seul groupe"
+	if len(membre.Artistes) > 1 {
+		texteGroupes = fmt.Sprintf("Membre de %d groupes", len(membre.Artistes))
+	}
+	labelGroupes := widget.NewLabel("👥 " + texteGroupes)
+
+	// les groupes avec les memes cards que l'accueil
+	grille := container.NewGridWrap(tailleCarte)
+	for _, id := range membre.Artistes {
+		if art, ok := a.artisteParID(id); ok {
+			grille.Add(a.creerCardArtiste(ctx, art))
+		}
+	}
+
+	contenu := container.NewVBox(
+		header,
+		widget.NewSeparator(),
+		labelGroupes,
+		grille,
+	)
+	return container.NewVScroll(contenu)
+}
+
+// creerListeMembres - les membres d'un artiste, chacun cliquable vers sa page
+// si le membre joue aussi ailleurs on le dit direct
+func (a *AppGroupie) creerListeMembres(artiste models.Artiste) *fyne.Container {
+	liste := container.NewVBox()
+	for _, nom := range artiste.Membres {
+		m, ok := a.membres.Membre(nom)
+		if !ok {
+			continue
+		}
+		texteBtn := "• " + m.Nom
+		if autres := len(m.Artistes) - 1; autres > 0 {
+			texteBtn += fmt.Sprintf("  (+%d autre groupe", autres)
+			if autres > 1 {
+				texteBtn += "s"
+			}
+			texteBtn += ")"
+		}
+		membre := m // capture pour la closure
+		btn := widget.NewButton(texteBtn, func() {
+			a.afficherMembre(membre.Nom)
+		})
+		btn.Importance = widget.LowImportance
+		btn.Alignment = widget.ButtonAlignLeading
+		liste.Add(btn)
+	}
+	return liste
+}
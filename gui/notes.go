@@ -0,0 +1,332 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// notes.go - les notes perso (en markdown) et les etoiles de 1 a 5 sur chaque artiste
+// avant l'equipe tenait ca dans un tableur a cote, maintenant c'est sur la page de l'artiste
+// tout est garde dans les preferences Fyne, on peut chercher dedans (note: et rating>=4),
+// trier la grille par etoiles, et tout exporter / importer en JSON depuis la page des notes
+
+// la cle dans les preferences Fyne
+const clePrefNotes = "notes"
+
+// maxEtoiles - la note max
+const maxEtoiles = 5
+
+// NoteArtiste - ce que l'utilisateur a ecrit sur un artiste
+type NoteArtiste struct {
+	Texte   string `json:"texte,omitempty"` // du markdown
+	Etoiles int    `json:"etoiles,omitempty"`
+}
+
+// NoteExportee - une note dans le fichier d'export, avec le nom comme pour les favoris
+type NoteExportee struct {
+	ID      int    `json:"id"`
+	Nom     string `json:"nom"`
+	Etoiles int    `json:"etoiles,omitempty"`
+	Texte   string `json:"texte,omitempty"`
+}
+
+// chargerNotes - lit les notes sauvees (au lancement)
+func (a *AppGroupie) chargerNotes() {
+	notes := make(map[int]NoteArtiste)
+	chargerPrefJSON(a.app.Preferences(), clePrefNotes, &notes)
+
+	a.notesMu.Lock()
+	a.notes = notes
+	a.notesMu.Unlock()
+}
+
+// sauverNotes - ecrit les notes dans les preferences
+func (a *AppGroupie) sauverNotes() {
+	a.notesMu.RLock()
+	defer a.notesMu.RUnlock()
+	sauverPrefJSON(a.app.Preferences(), clePrefNotes, a.notes)
+}
+
+// noteArtiste - la note d'un artiste (vide si y'en a pas)
+func (a *AppGroupie) noteArtiste(id int) NoteArtiste {
+	a.notesMu.RLock()
+	defer a.notesMu.RUnlock()
+	return a.notes[id]
+}
+
+// modifierNote - change la note d'un artiste et sauve; une note vide est supprimee
+func (a *AppGroupie) modifierNote(id int, modif func(n *NoteArtiste)) {
+	a.notesMu.Lock()
+	a.modifierNoteSansSauver(id, modif)
+	a.notesMu.Unlock()
+
+	a.sauverNotes()
+}
+
+// modifierNoteSansSauver - pareil sans sauver, notesMu doit etre pris par l'appelant
+// (pour un import on modifie tout et on sauve une seule fois a la fin)
+func (a *AppGroupie) modifierNoteSansSauver(id int, modif func(n *NoteArtiste)) {
+	note := a.notes[id]
+	modif(&note)
+	note.Texte = strings.TrimSpace(note.Texte)
+	note.Etoiles = min(max(note.Etoiles, 0), maxEtoiles)
+	if note == (NoteArtiste{}) {
+		delete(a.notes, id)
+	} else {
+		a.notes[id] = note
+	}
+}
+
+// idsNotes - les artistes qui ont une note, les mieux notes d'abord puis par nom
+func (a *AppGroupie) idsNotes() []int {
+	a.notesMu.RLock()
+	ids := make([]int, 0, len(a.notes))
+	for id := range a.notes {
+		ids = append(ids, id)
+	}
+	a.notesMu.RUnlock()
+
+	noms := make(map[int]string, len(ids))
+	for _, id := range ids {
+		if art, ok := a.artisteParID(id); ok {
+			noms[id] = texte.Normaliser(art.Nom)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ei, ej := a.noteArtiste(ids[i]).Etoiles, a.noteArtiste(ids[j]).Etoiles
+		if ei != ej {
+			return ei > ej
+		}
+		return noms[ids[i]] < noms[ids[j]]
+	})
+	return ids
+}
+
+// exporterNotes - toutes les notes avec le nom de l'artiste
+func (a *AppGroupie) exporterNotes() []NoteExportee {
+	var exportees []NoteExportee
+	for _, id := range a.idsNotes() {
+		art, ok := a.artisteParID(id)
+		if !ok {
+			continue
+		}
+		note := a.noteArtiste(id)
+		exportees = append(exportees, NoteExportee{ID: id, Nom: art.Nom, Etoiles: note.Etoiles, Texte: note.Texte})
+	}
+	return exportees
+}
+
+// importerNotes - les notes importees remplacent celles qu'on avait pour le meme artiste
+// l'artiste est retrouve comme pour les favoris: par l'ID si le nom colle, sinon par le nom
+func (a *AppGroupie) importerNotes(importees []NoteExportee) (importes int, introuvables []string) {
+	parNom := make(map[string]int, len(a.artistes))
+	for _, art := range a.artistes {
+		parNom[texte.Normaliser(art.Nom)] = art.ID
+	}
+
+	a.notesMu.Lock()
+	for _, n := range importees {
+		id, ok := 0, false
+		if art, trouve := a.artisteParID(n.ID); trouve && (n.Nom == "" || texte.Normaliser(art.Nom) == texte.Normaliser(n.Nom)) {
+			id, ok = art.ID, true
+		} else {
+			id, ok = parNom[texte.Normaliser(n.Nom)]
+		}
+		if !ok {
+			introuvables = append(introuvables, n.Nom)
+			continue
+		}
+		note := n // capture pour la closure
+		a.modifierNoteSansSauver(id, func(courante *NoteArtiste) {
+			*courante = NoteArtiste{Texte: note.Texte, Etoiles: note.Etoiles}
+		})
+		importes++
+	}
+	a.notesMu.Unlock()
+
+	// une seule ecriture des preferences pour tout l'import
+	a.sauverNotes()
+	return importes, introuvables
+}
+
+// texteEtoiles - "★★★☆☆"
+func texteEtoiles(n int) string {
+	return strings.Repeat("★", n) + strings.Repeat("☆", maxEtoiles-n)
+}
+
+// creerSectionNotes - sur la page de detail: les etoiles et la note en markdown
+// la note s'affiche rendue, le bouton Modifier passe en edition
+func (a *AppGroupie) creerSectionNotes(id int) fyne.CanvasObject {
+	// === ETOILES ===
+	labelNote := widget.NewLabel("⭐ Ma note:")
+	labelNote.TextStyle = fyne.TextStyle{Bold: true}
+
+	etoiles := container.NewHBox(labelNote)
+	boutons := make([]*widget.Button, maxEtoiles)
+	majEtoiles := func() {
+		n := a.noteArtiste(id).Etoiles
+		for i, btn := range boutons {
+			if i < n {
+				btn.SetText("★")
+			} else {
+				btn.SetText("☆")
+			}
+		}
+	}
+	for i := range boutons {
+		valeur := i + 1
+		boutons[i] = widget.NewButton("☆", func() {
+			a.modifierNote(id, func(n *NoteArtiste) {
+				// recliquer sur la note actuelle l'enleve
+				if n.Etoiles == valeur {
+					n.Etoiles = 0
+				} else {
+					n.Etoiles = valeur
+				}
+			})
+			majEtoiles()
+		})
+		boutons[i].Importance = widget.LowImportance
+		etoiles.Add(boutons[i])
+	}
+	majEtoiles()
+
+	// === NOTE MARKDOWN ===
+	labelTexte := widget.NewLabel("📝 Mes notes:")
+	labelTexte.TextStyle = fyne.TextStyle{Bold: true}
+
+	rendu := widget.NewRichTextFromMarkdown("")
+	rendu.Wrapping = fyne.TextWrapWord
+	majRendu := func() {
+		texteNote := a.noteArtiste(id).Texte
+		if texteNote == "" {
+			texteNote = "*Pas encore de notes*"
+		}
+		rendu.ParseMarkdown(texteNote)
+	}
+	majRendu()
+
+	editeur := widget.NewMultiLineEntry()
+	editeur.SetPlaceHolder("Des notes en markdown: **gras**, *italique*, - listes, # titres...")
+	editeur.Wrapping = fyne.TextWrapWord
+	editeur.SetMinRowsVisible(6)
+	editeur.Hide()
+
+	var btnModifier, btnAnnuler *widget.Button
+	btnModifier = widget.NewButtonWithIcon("Modifier", theme.DocumentCreateIcon(), func() {
+		if editeur.Visible() {
+			// on enregistre
+			a.modifierNote(id, func(n *NoteArtiste) { n.Texte = editeur.Text })
+			majRendu()
+			editeur.Hide()
+			btnAnnuler.Hide()
+			rendu.Show()
+			btnModifier.SetText("Modifier")
+			btnModifier.SetIcon(theme.DocumentCreateIcon())
+			return
+		}
+		editeur.SetText(a.noteArtiste(id).Texte)
+		rendu.Hide()
+		editeur.Show()
+		btnAnnuler.Show()
+		btnModifier.SetText("Enregistrer")
+		btnModifier.SetIcon(theme.DocumentSaveIcon())
+		a.fenetre.Canvas().Focus(editeur)
+	})
+	btnAnnuler = widget.NewButtonWithIcon("Annuler", theme.CancelIcon(), func() {
+		editeur.Hide()
+		btnAnnuler.Hide()
+		rendu.Show()
+		btnModifier.SetText("Modifier")
+		btnModifier.SetIcon(theme.DocumentCreateIcon())
+	})
+	btnAnnuler.Hide()
+
+	return container.NewVBox(
+		etoiles,
+		container.NewHBox(labelTexte, layout.NewSpacer(), btnAnnuler, btnModifier),
+		rendu,
+		editeur,
+	)
+}
+
+// afficherNotes - affiche la page de toutes les notes
+func (a *AppGroupie) afficherNotes() {
+	a.naviguer(EtatPage{Page: pageNotes})
+}
+
+// creerPageNotes - tous les artistes notes, les mieux notes d'abord, avec l'export / import
+func (a *AppGroupie) creerPageNotes() fyne.CanvasObject {
+	boutonsNav := a.creerBoutonsNavigation()
+
+	ids := a.idsNotes()
+	titre := widget.NewLabel(fmt.Sprintf("📝 Mes notes (%d)", len(ids)))
+	titre.TextStyle = fyne.TextStyle{Bold: true}
+
+	btnExporter := widget.NewButtonWithIcon("Exporter", theme.UploadIcon(), func() {
+		exporterJSON(a.fenetre, "notes-groupie.json", a.exporterNotes())
+	})
+	btnImporter := widget.NewButtonWithIcon("Importer", theme.DownloadIcon(), func() {
+		var importees []NoteExportee
+		importerJSON(a.fenetre, &importees, func() {
+			importes, introuvables := a.importerNotes(importees)
+			message := fmt.Sprintf("%d note(s) importée(s)", importes)
+			if len(introuvables) > 0 {
+				message += fmt.Sprintf("\n%d artiste(s) introuvable(s)", len(introuvables))
+			}
+			dialog.ShowInformation("Import des notes", message, a.fenetre)
+			a.rafraichirPage()
+		})
+	})
+	header := container.NewHBox(boutonsNav, titre, layout.NewSpacer(), btnExporter, btnImporter)
+
+	liste := container.NewVBox()
+	if len(ids) == 0 {
+		vide := widget.NewLabel("Pas encore de notes, ça se passe sur la page d'un artiste")
+		vide.Alignment = fyne.TextAlignCenter
+		liste.Add(vide)
+	}
+	for _, id := range ids {
+		art, ok := a.artisteParID(id)
+		if !ok {
+			continue
+		}
+		artiste := art // capture pour la closure
+		note := a.noteArtiste(id)
+
+		btnArtiste := widget.NewButton(artiste.Nom, func() {
+			a.ouvrirArtiste(artiste)
+		})
+		btnArtiste.Importance = widget.LowImportance
+		btnArtiste.Alignment = widget.ButtonAlignLeading
+
+		ligne := container.NewHBox(btnArtiste)
+		if note.Etoiles > 0 {
+			ligne.Add(widget.NewLabel(texteEtoiles(note.Etoiles)))
+		}
+		liste.Add(ligne)
+		if note.Texte != "" {
+			rendu := widget.NewRichTextFromMarkdown(note.Texte)
+			rendu.Wrapping = fyne.TextWrapWord
+			liste.Add(rendu)
+		}
+		liste.Add(widget.NewSeparator())
+	}
+
+	contenu := container.NewVBox(
+		header,
+		widget.NewSeparator(),
+		liste,
+	)
+	return container.NewVScroll(contenu)
+}
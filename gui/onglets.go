@@ -0,0 +1,182 @@
+package gui
+
+import (
+	"context"
+
+	"groupie-tracker/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// onglets.go - les pages d'artistes ouvertes dans des onglets a cote de la page principale
+// avant ouvrir un artiste remplacait tout, pour comparer deux groupes il fallait faire
+// des allers-retours; maintenant Ctrl+clic (ou clic molette sur une card) ouvre l'artiste
+// dans un nouvel onglet, et les onglets ouverts sont rouverts au prochain lancement
+// le premier onglet c'est la page principale, celle du routeur (precedent / suivant),
+// il se ferme pas; tant qu'il y a qu'elle on affiche pas de barre d'onglets du tout
+
+// la cle dans les preferences Fyne
+const clePrefOnglets = "onglets"
+
+// ongletArtiste - un artiste ouvert dans un onglet
+// la page est construite seulement quand on va sur l'onglet (les onglets rouverts au
+// lancement ne telechargent rien tant qu'on les regarde pas)
+type ongletArtiste struct {
+	artiste   models.Artiste
+	item      *container.TabItem
+	construit bool
+	annuler   context.CancelFunc // arrete les chargements de la page de l'onglet (carte...)
+}
+
+// afficherPage - met une page dans la zone principale (la fenetre, ou le premier onglet)
+func (a *AppGroupie) afficherPage(page fyne.CanvasObject) {
+	a.pagePrincipale = page
+	if a.onglets == nil {
+		a.fenetre.SetContent(page)
+		return
+	}
+	a.ongletPrincipal.Content = page
+	a.onglets.Select(a.ongletPrincipal)
+	a.onglets.Refresh()
+}
+
+// ctrlEnfonce - Ctrl est tenu en ce moment (pour Ctrl+clic), faux hors bureau
+func (a *AppGroupie) ctrlEnfonce() bool {
+	if d, ok := a.app.Driver().(desktop.Driver); ok {
+		return d.CurrentKeyModifiers()&fyne.KeyModifierControl != 0
+	}
+	return false
+}
+
+// ouvrirArtiste - ouvre la page de l'artiste, dans un nouvel onglet si Ctrl est enfonce
+func (a *AppGroupie) ouvrirArtiste(artiste models.Artiste) {
+	if a.ctrlEnfonce() {
+		a.ouvrirOnglet(artiste, true)
+		return
+	}
+	a.afficherDetail(artiste)
+}
+
+// ouvrirOnglet - ouvre l'artiste dans un onglet (ou va sur son onglet s'il est deja ouvert)
+func (a *AppGroupie) ouvrirOnglet(artiste models.Artiste, selectionner bool) {
+	for _, o := range a.ongletsArtistes {
+		if o.artiste.ID == artiste.ID {
+			if selectionner {
+				a.onglets.Select(o.item)
+			}
+			return
+		}
+	}
+
+	if a.onglets == nil {
+		a.creerOnglets()
+	}
+
+	o := &ongletArtiste{artiste: artiste}
+	o.item = container.NewTabItemWithIcon(artiste.Nom, theme.AccountIcon(), widget.NewLabel("⏳ Chargement..."))
+	a.ongletsArtistes = append(a.ongletsArtistes, o)
+	a.onglets.Append(o.item)
+	if selectionner {
+		a.onglets.Select(o.item)
+	}
+	a.sauverOnglets()
+}
+
+// creerOnglets - passe de la page seule aux onglets (la page principale devient le premier)
+func (a *AppGroupie) creerOnglets() {
+	a.ongletPrincipal = container.NewTabItemWithIcon("Groupie Tracker", theme.HomeIcon(), a.pagePrincipale)
+	a.onglets = container.NewDocTabs(a.ongletPrincipal)
+
+	// le premier onglet se ferme pas, les autres si
+	a.onglets.CloseIntercept = func(item *container.TabItem) {
+		if item != a.ongletPrincipal {
+			a.fermerOnglet(item)
+		}
+	}
+	a.onglets.OnSelected = func(item *container.TabItem) {
+		for _, o := range a.ongletsArtistes {
+			if o.item == item && !o.construit {
+				a.construireOnglet(o)
+			}
+		}
+	}
+	a.fenetre.SetContent(a.onglets)
+}
+
+// construireOnglet - cree (ou recree) la page de l'artiste dans son onglet
+func (a *AppGroupie) construireOnglet(o *ongletArtiste) {
+	if o.annuler != nil {
+		o.annuler()
+	}
+	var ctx context.Context
+	ctx, o.annuler = context.WithCancel(context.Background())
+	o.construit = true
+	o.item.Content = a.creerPageDetail(ctx, o.artiste, o)
+	a.onglets.Refresh()
+}
+
+// fermerOnglet - ferme l'onglet d'un artiste; sans onglet on revient a la page seule
+func (a *AppGroupie) fermerOnglet(item *container.TabItem) {
+	for i, o := range a.ongletsArtistes {
+		if o.item == item {
+			if o.annuler != nil {
+				o.annuler()
+			}
+			a.ongletsArtistes = append(a.ongletsArtistes[:i], a.ongletsArtistes[i+1:]...)
+			break
+		}
+	}
+	a.onglets.Remove(item)
+	a.sauverOnglets()
+
+	if len(a.ongletsArtistes) == 0 {
+		a.onglets = nil
+		a.ongletPrincipal = nil
+		a.fenetre.SetContent(a.pagePrincipale)
+	}
+}
+
+// ongletAffiche - l'onglet d'artiste affiche, nil si c'est la page principale
+func (a *AppGroupie) ongletAffiche() *ongletArtiste {
+	if a.onglets == nil {
+		return nil
+	}
+	item := a.onglets.Selected()
+	for _, o := range a.ongletsArtistes {
+		if o.item == item {
+			return o
+		}
+	}
+	return nil
+}
+
+// fermerOngletCourant - Ctrl+W, rien si on est sur la page principale
+func (a *AppGroupie) fermerOngletCourant() {
+	if o := a.ongletAffiche(); o != nil {
+		a.fermerOnglet(o.item)
+	}
+}
+
+// sauverOnglets - les IDs des artistes ouverts, dans l'ordre des onglets
+func (a *AppGroupie) sauverOnglets() {
+	ids := make([]int, len(a.ongletsArtistes))
+	for i, o := range a.ongletsArtistes {
+		ids[i] = o.artiste.ID
+	}
+	sauverPrefJSON(a.app.Preferences(), clePrefOnglets, ids)
+}
+
+// restaurerOnglets - rouvre les onglets de la derniere fois (sans aller dessus)
+func (a *AppGroupie) restaurerOnglets() {
+	var ids []int
+	chargerPrefJSON(a.app.Preferences(), clePrefOnglets, &ids)
+	for _, id := range ids {
+		if art, ok := a.artisteParID(id); ok {
+			a.ouvrirOnglet(art, false)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// presets.go - les presets de recherche sauvegardes
+// genre "groupes de 3 des annees 70 qui ont joue en France": on sauve les filtres
+// + le texte de recherche sous un nom, et on peut les partager en JSON
+
+// la cle dans les preferences Fyne
+const clePrefPresets = "presets"
+
+// PresetRecherche - un preset nomme avec l'etat complet des filtres et de la recherche
+type PresetRecherche struct {
+	Nom       string  `json:"nom"`
+	Recherche string  `json:"recherche"`
+	Filtres   Filtres `json:"filtres"`
+}
+
+// chargerPresets - lit les presets depuis les preferences, tries par nom
+func (a *AppGroupie) chargerPresets() []PresetRecherche {
+	var presets []PresetRecherche
+	chargerPrefJSON(a.app.Preferences(), clePrefPresets, &presets)
+	sort.Slice(presets, func(i, j int) bool {
+		return texte.Normaliser(presets[i].Nom) < texte.Normaliser(presets[j].Nom)
+	})
+	return presets
+}
+
+// sauverPresets - ecrit les presets dans les preferences
+func (a *AppGroupie) sauverPresets(presets []PresetRecherche) {
+	sauverPrefJSON(a.app.Preferences(), clePrefPresets, presets)
+}
+
+// fusionnerPresets - ajoute les nouveaux presets, ceux qui ont le meme nom sont remplaces
+func fusionnerPresets(existants, nouveaux []PresetRecherche) []PresetRecherche {
+	resultat := make([]PresetRecherche, 0, len(existants)+len(nouveaux))
+	remplaces := make(map[string]bool)
+	for _, p := range nouveaux {
+		remplaces[p.Nom] = true
+	}
+	for _, p := range existants {
+		if !remplaces[p.Nom] {
+			resultat = append(resultat, p)
+		}
+	}
+	for _, p := range nouveaux {
+		if strings.TrimSpace(p.Nom) != "" {
+			resultat = append(resultat, p)
+		}
+	}
+	return resultat
+}
+
+// validerPreset - remet les filtres d'un preset dans les bornes des donnees
+// un preset importe a la main peut n'avoir que quelques champs: sans creationMin / creationMax
+// on aurait un intervalle 0 - 0 qui cache tous les artistes sans rien dire
+// un intervalle absent, inverse ou hors des donnees repasse aux bornes completes,
+// le reste est ramene dans les bornes; renvoie ce qui a ete corrige (vide si tout allait bien)
+func validerPreset(p PresetRecherche, bornes BornesFiltres) (PresetRecherche, []string) {
+	var problemes []string
+
+	intervalle := func(nom string, bas, haut *int, borneMin, borneMax int) {
+		if (*bas == 0 && *haut == 0) || *bas > *haut || *haut < borneMin || *bas > borneMax {
+			problemes = append(problemes, fmt.Sprintf("%s: intervalle %d - %d invalide, remis à %d - %d", nom, *bas, *haut, borneMin, borneMax))
+			*bas, *haut = borneMin, borneMax
+			return
+		}
+		*bas, *haut = max(*bas, borneMin), min(*haut, borneMax)
+	}
+
+	f := p.Filtres.Cloner()
+	intervalle("création", &f.CreationMin, &f.CreationMax, bornes.CreationMin, bornes.CreationMax)
+	intervalle("premier album", &f.AlbumMin, &f.AlbumMax, bornes.AlbumMin, bornes.AlbumMax)
+	for nb := range f.NbMembres {
+		if nb < bornes.MembresMin || nb > bornes.MembresMax {
+			problemes = append(problemes, fmt.Sprintf("%d membres: aucun artiste, filtre enlevé", nb))
+			delete(f.NbMembres, nb)
+		}
+	}
+	p.Filtres = f
+	return p, problemes
+}
+
+// creerBarrePresets - le menu deroulant des presets avec les boutons sauver / supprimer / exporter / importer
+// on le met au dessus du panneau de filtres
+func (a *AppGroupie) creerBarrePresets(etat *EtatFiltres, recherche *EntryRecherche) fyne.CanvasObject {
+	presets := a.chargerPresets()
+
+	noms := func() []string {
+		liste := make([]string, len(presets))
+		for i, p := range presets {
+			liste[i] = p.Nom
+		}
+		return liste
+	}
+
+	selectPresets := widget.NewSelect(noms(), func(nom string) {
+		for _, p := range presets {
+			if p.Nom == nom {
+				// les bornes ont pu changer depuis que le preset a ete sauve
+				p, _ = validerPreset(p, a.bornes)
+				etat.Remplacer(p.Filtres)
+				recherche.SetText(p.Recherche)
+				return
+			}
+		}
+	})
+	selectPresets.PlaceHolder = "📂 Presets..."
+
+	// apres chaque modif on resauve et on met a jour la liste
+	mettreAJour := func(nouveaux []PresetRecherche) {
+		a.sauverPresets(nouveaux)
+		presets = a.chargerPresets()
+		selectPresets.Options = noms()
+		selectPresets.Refresh()
+	}
+
+	btnSauver := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		entryNom := widget.NewEntry()
+		entryNom.SetText(selectPresets.Selected)
+		items := []*widget.FormItem{widget.NewFormItem("Nom", entryNom)}
+		dialog.ShowForm("Sauver le preset", "Sauver", "Annuler", items, func(ok bool) {
+			nom := strings.TrimSpace(entryNom.Text)
+			if !ok || nom == "" {
+				return
+			}
+			preset := PresetRecherche{Nom: nom, Recherche: recherche.Text, Filtres: etat.Filtres()}
+			mettreAJour(fusionnerPresets(presets, []PresetRecherche{preset}))
+			selectPresets.SetSelected(nom)
+		}, a.fenetre)
+	})
+
+	btnSupprimer := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		nom := selectPresets.Selected
+		if nom == "" {
+			return
+		}
+		dialog.ShowConfirm("Supprimer", "Supprimer le preset \""+nom+"\" ?", func(ok bool) {
+			if !ok {
+				return
+			}
+			var restants []PresetRecherche
+			for _, p := range presets {
+				if p.Nom != nom {
+					restants = append(restants, p)
+				}
+			}
+			selectPresets.ClearSelected()
+			mettreAJour(restants)
+		}, a.fenetre)
+	})
+
+	btnExporter := widget.NewButtonWithIcon("", theme.UploadIcon(), func() {
+		exporterJSON(a.fenetre, "presets-groupie.json", presets)
+	})
+
+	btnImporter := widget.NewButtonWithIcon("", theme.DownloadIcon(), func() {
+		var importes []PresetRecherche
+		importerJSON(a.fenetre, &importes, func() {
+			var valides []PresetRecherche
+			var problemes []string
+			for _, p := range importes {
+				if strings.TrimSpace(p.Nom) == "" {
+					problemes = append(problemes, "un preset sans nom a été ignoré")
+					continue
+				}
+				corrige, erreurs := validerPreset(p, a.bornes)
+				for _, e := range erreurs {
+					problemes = append(problemes, fmt.Sprintf("\"%s\" %s", p.Nom, e))
+				}
+				valides = append(valides, corrige)
+			}
+			mettreAJour(fusionnerPresets(presets, valides))
+			if len(problemes) > 0 {
+				message := fmt.Sprintf("%d preset(s) importé(s), corrigé(s) au passage:\n%s", len(valides), strings.Join(problemes, "\n"))
+				dialog.ShowInformation("Import des presets", message, a.fenetre)
+			}
+		})
+	})
+
+	boutons := container.NewHBox(btnSauver, btnSupprimer, btnExporter, btnImporter)
+	return container.NewBorder(nil, nil, nil, boutons, selectPresets)
+}
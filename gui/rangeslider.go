@@ -0,0 +1,307 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// rangeslider.go - un slider avec deux poignees (min et max) sur la meme piste
+// avec un petit histogramme au dessus pour voir combien d'artistes il y a par annee
+// avant on avait deux widget.Slider l'un sous l'autre, c'etait pas ouf
+
+const (
+	hauteurHisto    = float32(36) // la zone des barres de l'histogramme
+	hauteurTexteSur = float32(14) // la ligne au dessus pour afficher l'annee survolee
+	taillePoignee   = float32(16)
+	epaisseurPiste  = float32(4)
+)
+
+// RangeSlider - un widget pour choisir un intervalle d'entiers [Bas, Haut] entre Min et Max
+type RangeSlider struct {
+	widget.BaseWidget
+
+	Min, Max    int
+	Bas, Haut   int
+	Histogramme []int // le nombre d'elements pour chaque valeur, l'index 0 c'est Min
+
+	// OnChanged est appele a chaque fois que l'utilisateur bouge une poignee
+	OnChanged func(bas, haut int)
+	// OnChangeEnded est appele quand il lache la poignee (ou apres un clic sur la piste)
+	OnChangeEnded func()
+
+	poignee int // la poignee qu'on est en train de drag: 0 aucune, 1 bas, 2 haut
+	survol  int // l'index de la barre survolee, -1 si rien
+}
+
+// NewRangeSlider - cree un range slider avec les deux poignees aux extremites
+func NewRangeSlider(min, max int, histogramme []int, onChanged func(bas, haut int)) *RangeSlider {
+	r := &RangeSlider{
+		Min:         min,
+		Max:         max,
+		Bas:         min,
+		Haut:        max,
+		Histogramme: histogramme,
+		OnChanged:   onChanged,
+		survol:      -1,
+	}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// SetValeurs - place les poignees sans declencher OnChanged (pour resynchroniser l'UI)
+func (r *RangeSlider) SetValeurs(bas, haut int) {
+	r.Bas, r.Haut = r.borner(bas), r.borner(haut)
+	if r.Bas > r.Haut {
+		r.Bas, r.Haut = r.Haut, r.Bas
+	}
+	r.Refresh()
+}
+
+// borner - ramene une valeur entre Min et Max
+func (r *RangeSlider) borner(v int) int {
+	if v < r.Min {
+		return r.Min
+	}
+	if v > r.Max {
+		return r.Max
+	}
+	return v
+}
+
+// valeurA - convertit une position x (en pixels) en valeur sur la piste
+func (r *RangeSlider) valeurA(x float32) int {
+	largeur := r.Size().Width - taillePoignee
+	if largeur <= 0 || r.Max == r.Min {
+		return r.Min
+	}
+	ratio := (x - taillePoignee/2) / largeur
+	v := r.Min + int(ratio*float32(r.Max-r.Min)+0.5)
+	return r.borner(v)
+}
+
+// positionDe - l'inverse de valeurA, la position x du centre d'une valeur
+func (r *RangeSlider) positionDe(v int) float32 {
+	largeur := r.Size().Width - taillePoignee
+	if r.Max == r.Min {
+		return taillePoignee / 2
+	}
+	return taillePoignee/2 + largeur*float32(v-r.Min)/float32(r.Max-r.Min)
+}
+
+// deplacer - bouge la poignee active (ou la plus proche) vers une position
+func (r *RangeSlider) deplacer(x float32) {
+	v := r.valeurA(x)
+	if r.poignee == 0 {
+		// on prend la poignee la plus proche (celle du bas a egalite)
+		switch {
+		case v <= r.Bas:
+			r.poignee = 1
+		case v >= r.Haut:
+			r.poignee = 2
+		case v-r.Bas <= r.Haut-v:
+			r.poignee = 1
+		default:
+			r.poignee = 2
+		}
+	}
+
+	avantBas, avantHaut := r.Bas, r.Haut
+	if r.poignee == 1 {
+		r.Bas = v
+		if r.Bas > r.Haut {
+			r.Bas = r.Haut
+		}
+	} else {
+		r.Haut = v
+		if r.Haut < r.Bas {
+			r.Haut = r.Bas
+		}
+	}
+	if r.Bas == avantBas && r.Haut == avantHaut {
+		return
+	}
+
+	r.Refresh()
+	if r.OnChanged != nil {
+		r.OnChanged(r.Bas, r.Haut)
+	}
+}
+
+// Tapped - un clic sur la piste amene la poignee la plus proche
+func (r *RangeSlider) Tapped(ev *fyne.PointEvent) {
+	r.poignee = 0
+	r.deplacer(ev.Position.X)
+	r.poignee = 0
+	if r.OnChangeEnded != nil {
+		r.OnChangeEnded()
+	}
+}
+
+// Dragged - on suit la souris avec la poignee attrapee au debut du drag
+func (r *RangeSlider) Dragged(ev *fyne.DragEvent) {
+	r.deplacer(ev.Position.X)
+}
+
+// DragEnd - on lache la poignee
+func (r *RangeSlider) DragEnd() {
+	r.poignee = 0
+	if r.OnChangeEnded != nil {
+		r.OnChangeEnded()
+	}
+}
+
+// MouseIn - rien de special, on laisse MouseMoved gerer
+func (r *RangeSlider) MouseIn(ev *desktop.MouseEvent) {
+	r.MouseMoved(ev)
+}
+
+// MouseMoved - on retient la barre survolee pour afficher son nombre
+func (r *RangeSlider) MouseMoved(ev *desktop.MouseEvent) {
+	idx := r.valeurA(ev.Position.X) - r.Min
+	if idx != r.survol {
+		r.survol = idx
+		r.Refresh()
+	}
+}
+
+// MouseOut - plus rien de survole
+func (r *RangeSlider) MouseOut() {
+	r.survol = -1
+	r.Refresh()
+}
+
+// MinSize - assez large pour etre utilisable, assez haut pour l'histo + la piste
+func (r *RangeSlider) MinSize() fyne.Size {
+	return fyne.NewSize(200, hauteurTexteSur+hauteurHisto+taillePoignee+4)
+}
+
+// CreateRenderer - cree le renderer qui dessine tout
+func (r *RangeSlider) CreateRenderer() fyne.WidgetRenderer {
+	rend := &rendererRangeSlider{
+		slider:      r,
+		texteSurvol: canvas.NewText("", theme.Color(theme.ColorNameForeground)),
+		piste:       canvas.NewRectangle(theme.Color(theme.ColorNameInputBorder)),
+		pisteActive: canvas.NewRectangle(theme.Color(theme.ColorNamePrimary)),
+		poigneeBas:  canvas.NewCircle(theme.Color(theme.ColorNamePrimary)),
+		poigneeHaut: canvas.NewCircle(theme.Color(theme.ColorNamePrimary)),
+	}
+	rend.texteSurvol.TextSize = 11
+	rend.texteSurvol.Alignment = fyne.TextAlignCenter
+	rend.Refresh()
+	return rend
+}
+
+// rendererRangeSlider - le renderer du RangeSlider
+type rendererRangeSlider struct {
+	slider      *RangeSlider
+	barres      []*canvas.Rectangle
+	texteSurvol *canvas.Text
+	piste       *canvas.Rectangle
+	pisteActive *canvas.Rectangle
+	poigneeBas  *canvas.Circle
+	poigneeHaut *canvas.Circle
+}
+
+func (rend *rendererRangeSlider) Layout(taille fyne.Size) {
+	r := rend.slider
+
+	// l'histogramme: une barre par valeur, hauteur proportionnelle au max
+	maxHisto := 0
+	for _, nb := range r.Histogramme {
+		if nb > maxHisto {
+			maxHisto = nb
+		}
+	}
+	largeurBarre := float32(1)
+	if n := len(r.Histogramme); n > 0 {
+		largeurBarre = (taille.Width - taillePoignee) / float32(n)
+		if largeurBarre > 2 {
+			largeurBarre -= 1 // un pixel d'espace entre les barres
+		}
+	}
+	for i, barre := range rend.barres {
+		h := float32(0)
+		if maxHisto > 0 {
+			h = hauteurHisto * float32(r.Histogramme[i]) / float32(maxHisto)
+		}
+		x := r.positionDe(r.Min+i) - largeurBarre/2
+		barre.Resize(fyne.NewSize(largeurBarre, h))
+		barre.Move(fyne.NewPos(x, hauteurTexteSur+hauteurHisto-h))
+	}
+
+	rend.texteSurvol.Resize(fyne.NewSize(taille.Width, hauteurTexteSur))
+	rend.texteSurvol.Move(fyne.NewPos(0, 0))
+
+	yPiste := hauteurTexteSur + hauteurHisto + taillePoignee/2
+	rend.piste.Resize(fyne.NewSize(taille.Width-taillePoignee, epaisseurPiste))
+	rend.piste.Move(fyne.NewPos(taillePoignee/2, yPiste-epaisseurPiste/2))
+
+	xBas, xHaut := r.positionDe(r.Bas), r.positionDe(r.Haut)
+	rend.pisteActive.Resize(fyne.NewSize(xHaut-xBas, epaisseurPiste))
+	rend.pisteActive.Move(fyne.NewPos(xBas, yPiste-epaisseurPiste/2))
+
+	rend.poigneeBas.Resize(fyne.NewSize(taillePoignee, taillePoignee))
+	rend.poigneeBas.Move(fyne.NewPos(xBas-taillePoignee/2, yPiste-taillePoignee/2))
+	rend.poigneeHaut.Resize(fyne.NewSize(taillePoignee, taillePoignee))
+	rend.poigneeHaut.Move(fyne.NewPos(xHaut-taillePoignee/2, yPiste-taillePoignee/2))
+}
+
+func (rend *rendererRangeSlider) MinSize() fyne.Size {
+	return rend.slider.MinSize()
+}
+
+func (rend *rendererRangeSlider) Refresh() {
+	r := rend.slider
+
+	// on recree les barres si la taille de l'histogramme a change
+	if len(rend.barres) != len(r.Histogramme) {
+		rend.barres = make([]*canvas.Rectangle, len(r.Histogramme))
+		for i := range rend.barres {
+			rend.barres[i] = canvas.NewRectangle(color.Transparent)
+		}
+	}
+
+	// les barres dans l'intervalle choisi sont colorees, les autres grisees
+	primaire := theme.Color(theme.ColorNamePrimary)
+	grise := theme.Color(theme.ColorNameDisabled)
+	for i, barre := range rend.barres {
+		if r.Min+i >= r.Bas && r.Min+i <= r.Haut {
+			barre.FillColor = primaire
+		} else {
+			barre.FillColor = grise
+		}
+		barre.Refresh()
+	}
+
+	if r.survol >= 0 && r.survol < len(r.Histogramme) {
+		rend.texteSurvol.Text = fmt.Sprintf("%d: %d", r.Min+r.survol, r.Histogramme[r.survol])
+	} else {
+		rend.texteSurvol.Text = ""
+	}
+	rend.texteSurvol.Color = theme.Color(theme.ColorNameForeground)
+	rend.texteSurvol.Refresh()
+
+	rend.piste.FillColor = theme.Color(theme.ColorNameInputBorder)
+	rend.pisteActive.FillColor = primaire
+	rend.poigneeBas.FillColor = primaire
+	rend.poigneeHaut.FillColor = primaire
+
+	rend.Layout(r.Size())
+	canvas.Refresh(r)
+}
+
+func (rend *rendererRangeSlider) Objects() []fyne.CanvasObject {
+	objets := make([]fyne.CanvasObject, 0, len(rend.barres)+5)
+	for _, barre := range rend.barres {
+		objets = append(objets, barre)
+	}
+	return append(objets, rend.texteSurvol, rend.piste, rend.pisteActive, rend.poigneeBas, rend.poigneeHaut)
+}
+
+func (rend *rendererRangeSlider) Destroy() {}
@@ -0,0 +1,117 @@
+package gui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"groupie-tracker/models"
+	"groupie-tracker/recherche"
+
+	"fyne.io/fyne/v2"
+)
+
+// rechercheasync.go - la recherche se calcule en arriere plan
+// avant chaque frappe refaisait tout (suggestions + grille) direct dans le thread de l'UI,
+// avec beaucoup d'artistes ca ramait; maintenant on attend que la frappe se calme (debounce),
+// on calcule dans une goroutine, et si l'utilisateur a retape entre temps on jette le calcul
+
+// delaiRecherche - le temps sans frappe avant de lancer la recherche
+const delaiRecherche = 150 * time.Millisecond
+
+// tacheAnnulable - lance un calcul en arriere plan apres un delai,
+// relancer (ou annuler) abandonne le calcul precedent s'il tournait encore
+type tacheAnnulable struct {
+	mu       sync.Mutex
+	minuteur *time.Timer
+	annuler  context.CancelFunc
+}
+
+// Lancer - calcul tourne dans une goroutine et renvoie ce qu'il faut faire sur l'UI (ou nil)
+// ce qui est renvoye passe par fyne.Do, et seulement si personne a relance entre temps
+func (t *tacheAnnulable) Lancer(delai time.Duration, calcul func(ctx context.Context) func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.arreter()
+
+	ctx, annuler := context.WithCancel(context.Background())
+	t.annuler = annuler
+	t.minuteur = time.AfterFunc(delai, func() {
+		appliquer := calcul(ctx)
+		if appliquer == nil || ctx.Err() != nil {
+			return
+		}
+		fyne.Do(func() {
+			// on reverifie dans le thread de l'UI: une frappe a pu arriver pendant le trajet
+			if ctx.Err() == nil {
+				appliquer()
+			}
+		})
+	})
+}
+
+// Annuler - abandonne le calcul en attente ou en cours (quand on quitte la page par exemple)
+func (t *tacheAnnulable) Annuler() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.arreter()
+}
+
+func (t *tacheAnnulable) arreter() {
+	if t.minuteur != nil {
+		t.minuteur.Stop()
+	}
+	if t.annuler != nil {
+		t.annuler()
+	}
+}
+
+// resultatRecherche - tout ce que le calcul en arriere plan prepare pour la page d'accueil
+type resultatRecherche struct {
+	artistes    []models.Artiste
+	raisons     map[int]raisonCorrespondance
+	erreur      error                        // la requete est mal ecrite (on filtre pas dessus)
+	suggestions []models.SuggestionRecherche // nil si on a pas demande les suggestions
+}
+
+// calculerRecherche - filtres + requete + raisons + tri (+ suggestions si demande), sans toucher a l'UI
+// renvoie false si le calcul a ete annule en route
+func (a *AppGroupie) calculerRecherche(ctx context.Context, filtres Filtres, requete string, tri Tri, avecSuggestions bool) (resultatRecherche, bool) {
+	var res resultatRecherche
+
+	if avecSuggestions && requete != "" {
+		res.suggestions = genererSuggestionsRequete(requete, a.artistes, a.ficheArtiste, a.index)
+		if ctx.Err() != nil {
+			return res, false
+		}
+	}
+
+	// on applique d'abord les filtres
+	res.artistes = appliquerFiltres(a.artistes, &filtres, a.locationsData, a.etiquettesArtiste)
+
+	// puis la requete de recherche (texte libre ou champs genre member:"phil collins")
+	predicat, err := recherche.CompilerAvec(requete, a.artistesPourTexte)
+	if err != nil {
+		// requete pas valide: on filtre pas sur la recherche
+		res.erreur = err
+		res.artistes = a.trierArtistes(res.artistes, tri)
+		return res, ctx.Err() == nil
+	}
+	if ctx.Err() != nil {
+		return res, false
+	}
+
+	res.raisons = a.raisonsRecherche(requete)
+	var artistesRecherche []models.Artiste
+	for i, art := range res.artistes {
+		// de temps en temps on regarde si ca vaut encore le coup de continuer
+		if i%256 == 0 && ctx.Err() != nil {
+			return res, false
+		}
+		if predicat(a.ficheArtiste(art.ID)) {
+			artistesRecherche = append(artistesRecherche, art)
+		}
+	}
+	res.artistes = a.trierArtistes(artistesRecherche, tri)
+	return res, ctx.Err() == nil
+}
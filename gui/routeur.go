@@ -0,0 +1,183 @@
+package gui
+
+import (
+	"context"
+
+	"groupie-tracker/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// routeur.go - la navigation entre les pages avec un historique precedent / suivant
+// avant chaque page remplacait juste le contenu de la fenetre et "Retour" reconstruisait
+// un accueil tout neuf: la recherche, les filtres et le scroll etaient perdus
+// maintenant chaque page est decrite par un EtatPage, on garde la pile des etats,
+// et en quittant une page on note ou on en etait pour pouvoir y revenir pareil
+
+// les pages possibles
+const (
+	pageAccueil     = "accueil"
+	pageArtiste     = "artiste"
+	pageMembre      = "membre"
+	pageLieu        = "lieu"
+	pageFavoris     = "favoris"
+	pageNotes       = "notes"
+	pageComparaison = "comparaison"
+)
+
+// maxPagesHistorique - au dela on oublie les plus vieilles pages
+const maxPagesHistorique = 50
+
+// EtatPage - tout ce qu'il faut pour reafficher une page comme on l'a laissee
+type EtatPage struct {
+	Page      string `json:"page"`
+	ArtisteID int    `json:"artiste,omitempty"`  // pour la page d'un artiste
+	Cle       string `json:"cle,omitempty"`      // le nom du membre, ou le lieu de l'API ("london-uk")
+	Artistes  []int  `json:"artistes,omitempty"` // les artistes compares
+
+	// l'accueil (rempli en le quittant)
+	Recherche  string   `json:"recherche,omitempty"`
+	Filtres    *Filtres `json:"filtres,omitempty"` // nil = les filtres par defaut
+	Defilement float32  `json:"defilement,omitempty"`
+}
+
+// navigation - la pile des pages visitees, position c'est la page affichee
+type navigation struct {
+	pages    []EtatPage
+	position int
+}
+
+// naviguer - va sur une nouvelle page; tout ce qu'on pouvait refaire avec "suivant" est oublie
+func (a *AppGroupie) naviguer(etat EtatPage) {
+	a.memoriserPage()
+
+	n := &a.navigation
+	if len(n.pages) > 0 {
+		n.pages = n.pages[:n.position+1]
+	}
+	n.pages = append(n.pages, etat)
+	if len(n.pages) > maxPagesHistorique {
+		n.pages = n.pages[len(n.pages)-maxPagesHistorique:]
+	}
+	n.position = len(n.pages) - 1
+
+	a.afficherEtat(etat)
+}
+
+// peutReculer / peutAvancer - pour griser les boutons
+func (a *AppGroupie) peutReculer() bool {
+	return a.navigation.position > 0
+}
+
+func (a *AppGroupie) peutAvancer() bool {
+	return a.navigation.position < len(a.navigation.pages)-1
+}
+
+// pagePrecedente - revient a la page d'avant, comme on l'avait laissee
+func (a *AppGroupie) pagePrecedente() {
+	if !a.peutReculer() {
+		return
+	}
+	a.memoriserPage()
+	a.navigation.position--
+	a.afficherEtat(a.navigation.pages[a.navigation.position])
+}
+
+// pageSuivante - refait la page d'apres
+func (a *AppGroupie) pageSuivante() {
+	if !a.peutAvancer() {
+		return
+	}
+	a.memoriserPage()
+	a.navigation.position++
+	a.afficherEtat(a.navigation.pages[a.navigation.position])
+}
+
+// rafraichirPage - reconstruit la page affichee sans toucher a l'historique
+// (apres un changement de favori, un import...)
+func (a *AppGroupie) rafraichirPage() {
+	if len(a.navigation.pages) == 0 {
+		return
+	}
+	a.memoriserPage()
+	a.afficherEtat(a.navigation.pages[a.navigation.position])
+}
+
+// memoriserPage - la page affichee note ou on en est avant qu'on la quitte
+func (a *AppGroupie) memoriserPage() {
+	if a.capturerPage != nil && len(a.navigation.pages) > 0 {
+		a.capturerPage(&a.navigation.pages[a.navigation.position])
+	}
+}
+
+// afficherEtat - construit la page decrite par l'etat et la met dans la fenetre
+// si l'artiste (ou le membre) existe plus on retombe sur l'accueil
+func (a *AppGroupie) afficherEtat(etat EtatPage) {
+	a.quitterAccueil()
+	a.capturerPage = nil
+
+	// les chargements en arriere plan de l'ancienne page s'arretent, la nouvelle a son contexte
+	if a.annulerPage != nil {
+		a.annulerPage()
+	}
+	ctx, annuler := context.WithCancel(context.Background())
+	a.annulerPage = annuler
+
+	var page fyne.CanvasObject
+	switch etat.Page {
+	case pageArtiste:
+		if art, ok := a.artisteParID(etat.ArtisteID); ok {
+			// on se souvient de l'artiste
+			a.ajouterRecent(art.ID)
+			page = a.creerPageDetail(ctx, art, nil)
+		}
+	case pageMembre:
+		if membre, ok := a.membres.Membre(etat.Cle); ok {
+			page = a.creerPageMembre(ctx, membre)
+		}
+	case pageLieu:
+		page = a.creerPageLieu(ctx, etat.Cle)
+	case pageFavoris:
+		page = a.creerPageFavoris(ctx)
+	case pageNotes:
+		page = a.creerPageNotes()
+	case pageComparaison:
+		var artistes []models.Artiste
+		for _, id := range etat.Artistes {
+			if art, ok := a.artisteParID(id); ok {
+				artistes = append(artistes, art)
+			}
+		}
+		if len(artistes) >= minComparaison {
+			page = a.creerPageComparaison(ctx, artistes)
+		}
+	}
+	if page == nil {
+		page = a.creerPageAccueil(etat)
+	}
+	a.afficherPage(page)
+}
+
+// creerBoutonsNavigation - les boutons precedent / suivant en haut de chaque page
+// s'il y a rien avant (on arrive direct sur une page), "Retour" ramene a l'accueil
+func (a *AppGroupie) creerBoutonsNavigation() fyne.CanvasObject {
+	btnRetour := widget.NewButtonWithIcon("Retour", theme.NavigateBackIcon(), func() {
+		if a.peutReculer() {
+			a.pagePrecedente()
+		} else {
+			a.afficherAccueil()
+		}
+	})
+	// sur l'accueil sans rien avant, retourner a l'accueil servirait a rien
+	if !a.peutReculer() && (len(a.navigation.pages) == 0 || a.navigation.pages[a.navigation.position].Page == pageAccueil) {
+		btnRetour.Disable()
+	}
+	btnSuivant := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), a.pageSuivante)
+	if !a.peutAvancer() {
+		btnSuivant.Disable()
+	}
+	return container.NewHBox(btnRetour, btnSuivant)
+}
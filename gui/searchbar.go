@@ -4,10 +4,15 @@ import (
 	"fmt"
 	"strings"
 
+	"groupie-tracker/geo"
+	"groupie-tracker/index"
 	"groupie-tracker/models"
+	"groupie-tracker/recherche"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -15,99 +20,280 @@ import (
 // c'est un peu le truc le plus chiant a faire mais ca marche bien au final
 
 // EntryRecherche - un widget Entry customise pour la recherche
-// avec les suggestions qui s'affichent en dessous
+// avec les suggestions qui s'affichent en dessous (fleches, Entree, Echap)
+// les suggestions sont dans un calque de la page et pas dans un popup: un popup est un overlay
+// qui prend le clavier, la barre perdait le focus (et son curseur) pendant qu'on tapait
 type EntryRecherche struct {
 	widget.Entry
 	onChanged func(string)
+
+	// OnSelection - appele quand on choisit une suggestion (clic ou Entree)
+	OnSelection func(models.SuggestionRecherche)
+	// OnFocusVide - appele quand la barre prend le focus alors qu'elle est vide (pour l'historique)
+	OnFocusVide func()
+
+	calque *fyne.Container // a mettre par dessus la page (voir Calque)
+	cadre  *fyne.Container // le fond + la liste, place sous la barre dans le calque
+	fond   *canvas.Rectangle
+	liste  *listeSuggestions
 }
 
 // NewEntryRecherche - cree une nouvelle barre de recherche
 func NewEntryRecherche() *EntryRecherche {
 	e := &EntryRecherche{}
 	e.ExtendBaseWidget(e)
-	e.PlaceHolder = "🔍 Rechercher un artiste, membre, lieu..."
+	e.PlaceHolder = "🔍 Rechercher un artiste, membre, lieu... (ou country:uk created:1970..1980 concerts>10)"
+	e.liste = newListeSuggestions(e)
+	e.fond = canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground))
+	e.fond.StrokeWidth = 1
+	e.cadre = container.NewStack(e.fond, e.liste)
+	e.cadre.Hide()
+	e.calque = container.NewWithoutLayout(e.cadre)
 	return e
 }
 
-// genererSuggestions - genere toutes les suggestions basees sur le texte tape
-// gere les cas: nom d'artiste, membres, locations, date 1er album, date creation
-func genererSuggestions(texte string, artistes []models.Artiste, locData models.IndexLocations) []models.SuggestionRecherche {
-	if texte == "" {
-		return nil
+// Calque - le calque des suggestions, a empiler par dessus la page qui contient la barre
+// (container.NewStack(page, barre.Calque())); les clics a cote de la liste passent a travers
+func (e *EntryRecherche) Calque() fyne.CanvasObject {
+	return e.calque
+}
+
+// FocusGained - comme l'Entry normale, mais previent si la barre est vide
+func (e *EntryRecherche) FocusGained() {
+	e.Entry.FocusGained()
+	if e.Text == "" && e.OnFocusVide != nil {
+		e.OnFocusVide()
 	}
+}
 
-	var suggestions []models.SuggestionRecherche
-	texteMin := strings.ToLower(strings.TrimSpace(texte))
+// FocusLost - on a clique ailleurs, les suggestions se ferment
+// (un clic sur une suggestion enleve pas le focus, le bouton est focusable)
+func (e *EntryRecherche) FocusLost() {
+	e.Entry.FocusLost()
+	e.CacherSuggestions()
+}
 
-	// on va checker chaque artiste pour chaque type de recherche
-	for _, artiste := range artistes {
-		// recherche par nom d'artiste/groupe
-		if strings.Contains(strings.ToLower(artiste.Nom), texteMin) {
-			suggestions = append(suggestions, models.SuggestionRecherche{
-				Texte:     artiste.Nom + " → artist/band",
-				Type:      "artist/band",
-				ArtisteID: artiste.ID,
-			})
+// TypedKey - quand les suggestions sont ouvertes: haut / bas pour choisir, Entree pour ouvrir,
+// Echap pour fermer; le reste (et tout quand elles sont fermees) c'est l'Entry normale
+func (e *EntryRecherche) TypedKey(ev *fyne.KeyEvent) {
+	if e.SuggestionsVisibles() {
+		switch ev.Name {
+		case fyne.KeyDown:
+			e.liste.deplacer(1)
+			return
+		case fyne.KeyUp:
+			e.liste.deplacer(-1)
+			return
+		case fyne.KeyReturn, fyne.KeyEnter:
+			if e.liste.choisirSelection() {
+				return
+			}
+		case fyne.KeyEscape:
+			e.CacherSuggestions()
+			return
 		}
+	}
+	e.Entry.TypedKey(ev)
+}
 
-		// recherche par membre
-		for _, membre := range artiste.Membres {
-			membreClean := strings.TrimSpace(membre)
-			if strings.Contains(strings.ToLower(membreClean), texteMin) {
-				suggestions = append(suggestions, models.SuggestionRecherche{
-					Texte:     membreClean + " → member (" + artiste.Nom + ")",
-					Type:      "member",
-					ArtisteID: artiste.ID,
-				})
-			}
+// AfficherSuggestions - met a jour les suggestions sous la barre, les cache s'il y en a pas
+func (e *EntryRecherche) AfficherSuggestions(suggestions []models.SuggestionRecherche) {
+	if len(suggestions) == 0 {
+		e.CacherSuggestions()
+		return
+	}
+	d := fyne.CurrentApp().Driver()
+	if d.CanvasForObject(e) == nil || d.CanvasForObject(e.calque) == nil {
+		return // pas encore a l'ecran (ou le calque a pas ete mis dans la page)
+	}
+	e.liste.remplir(suggestions)
+
+	// juste sous la barre, de la meme largeur, et pas plus haut que 10 lignes environ
+	pos := d.AbsolutePositionForObject(e).Subtract(d.AbsolutePositionForObject(e.calque))
+	hauteur := e.liste.boite.MinSize().Height + theme.Padding()*2
+	if maxi := e.Size().Height * 10; hauteur > maxi {
+		hauteur = maxi
+	}
+	e.fond.FillColor = theme.Color(theme.ColorNameOverlayBackground)
+	e.fond.StrokeColor = theme.Color(theme.ColorNameShadow)
+	e.cadre.Resize(fyne.NewSize(e.Size().Width, hauteur))
+	e.cadre.Move(pos.Add(fyne.NewPos(0, e.Size().Height)))
+	e.cadre.Show()
+	e.calque.Refresh()
+}
+
+// CacherSuggestions - ferme la liste des suggestions
+func (e *EntryRecherche) CacherSuggestions() {
+	e.cadre.Hide()
+}
+
+// SuggestionsVisibles - la liste des suggestions est ouverte
+func (e *EntryRecherche) SuggestionsVisibles() bool {
+	return e.cadre.Visible()
+}
+
+// titresGroupes - le titre affiche au dessus de chaque type de suggestion
+var titresGroupes = map[string]string{
+	index.TypeArtiste:    "🎸 Artistes",
+	index.TypeMembre:     "🎤 Membres",
+	index.TypeLieu:       "📍 Lieux",
+	index.TypeConcert:    "🎫 Concerts",
+	index.TypeCreation:   "📅 Dates de création",
+	index.TypeAlbum:      "💿 Premiers albums",
+	typeRechercheRecente: "🕘 Recherches récentes",
+	typeArtisteRecent:    "👁 Récemment consultés",
+}
+
+// grouperSuggestions - regroupe par type en gardant l'ordre de pertinence:
+// le groupe du meilleur resultat vient en premier, et dans chaque groupe rien ne bouge
+func grouperSuggestions(suggestions []models.SuggestionRecherche) [][]models.SuggestionRecherche {
+	var groupes [][]models.SuggestionRecherche
+	position := make(map[string]int)
+	for _, s := range suggestions {
+		i, ok := position[s.Type]
+		if !ok {
+			i = len(groupes)
+			position[s.Type] = i
+			groupes = append(groupes, nil)
 		}
+		groupes[i] = append(groupes[i], s)
+	}
+	return groupes
+}
 
-		// recherche par date du premier album
-		if strings.Contains(strings.ToLower(artiste.PremierAlbum), texteMin) {
-			suggestions = append(suggestions, models.SuggestionRecherche{
-				Texte:     artiste.PremierAlbum + " → first album date (" + artiste.Nom + ")",
-				Type:      "first album date",
-				ArtisteID: artiste.ID,
-			})
+// listeSuggestions - la liste sous la barre: les suggestions groupees avec une selection au clavier
+// le focus reste sur la barre, c'est elle qui fait bouger la selection (voir EntryRecherche.TypedKey)
+type listeSuggestions struct {
+	widget.BaseWidget
+	entry *EntryRecherche
+
+	suggestions []models.SuggestionRecherche // dans l'ordre d'affichage (apres le groupement)
+	boutons     []*widget.Button
+	selection   int
+
+	boite  *fyne.Container
+	scroll *container.Scroll
+}
+
+func newListeSuggestions(entry *EntryRecherche) *listeSuggestions {
+	l := &listeSuggestions{entry: entry, boite: container.NewVBox()}
+	l.scroll = container.NewVScroll(l.boite)
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+func (l *listeSuggestions) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(l.scroll)
+}
+
+// remplir - reconstruit la liste avec les titres de groupe, la selection revient en haut
+func (l *listeSuggestions) remplir(suggestions []models.SuggestionRecherche) {
+	l.suggestions = l.suggestions[:0]
+	l.boutons = l.boutons[:0]
+	l.boite.RemoveAll()
+
+	for _, groupe := range grouperSuggestions(suggestions) {
+		titre := titresGroupes[groupe[0].Type]
+		if titre == "" {
+			titre = groupe[0].Type
 		}
+		labelTitre := widget.NewLabel(titre)
+		labelTitre.TextStyle = fyne.TextStyle{Bold: true}
+		l.boite.Add(labelTitre)
 
-		// recherche par date de creation
-		dateCreStr := fmt.Sprintf("%d", artiste.DateCreation)
-		if strings.Contains(dateCreStr, texteMin) {
-			suggestions = append(suggestions, models.SuggestionRecherche{
-				Texte:     fmt.Sprintf("%d → creation date (%s)", artiste.DateCreation, artiste.Nom),
-				Type:      "creation date",
-				ArtisteID: artiste.ID,
+		for _, s := range groupe {
+			suggestion := s // capture pour la closure, sinon bug classique
+			btn := widget.NewButton(suggestion.Texte, func() {
+				l.choisir(suggestion)
 			})
+			btn.Alignment = widget.ButtonAlignLeading
+			l.suggestions = append(l.suggestions, suggestion)
+			l.boutons = append(l.boutons, btn)
+			l.boite.Add(btn)
 		}
 	}
 
-	// recherche par lieu de concert
-	dejavu := make(map[string]bool)
-	for _, loc := range locData.Index {
-		for _, lieu := range loc.Locations {
-			lieuPropre := strings.ReplaceAll(lieu, "_", " ")
-			lieuPropre = strings.ReplaceAll(lieuPropre, "-", ", ")
-			lieuMin := strings.ToLower(lieuPropre)
-
-			cleUnique := fmt.Sprintf("%s_%d", lieuPropre, loc.ID)
-			if strings.Contains(lieuMin, texteMin) && !dejavu[cleUnique] {
-				dejavu[cleUnique] = true
-
-				// trouver l'artiste correspondant
-				nomArtiste := "?"
-				for _, a := range artistes {
-					if a.ID == loc.ID {
-						nomArtiste = a.Nom
-						break
-					}
-				}
-				suggestions = append(suggestions, models.SuggestionRecherche{
-					Texte:     lieuPropre + " → location (" + nomArtiste + ")",
-					Type:      "location",
-					ArtisteID: loc.ID,
-				})
+	l.selection = 0
+	l.majSelection()
+	l.scroll.ScrollToTop()
+}
+
+// majSelection - met en avant le bouton selectionne et scrolle pour qu'il soit visible
+func (l *listeSuggestions) majSelection() {
+	for i, btn := range l.boutons {
+		if i == l.selection {
+			btn.Importance = widget.HighImportance
+		} else {
+			btn.Importance = widget.LowImportance
+		}
+		btn.Refresh()
+	}
+	if l.selection < 0 || l.selection >= len(l.boutons) {
+		return
+	}
+
+	btn := l.boutons[l.selection]
+	haut, bas := btn.Position().Y, btn.Position().Y+btn.Size().Height
+	switch {
+	case haut < l.scroll.Offset.Y:
+		l.scroll.ScrollToOffset(fyne.NewPos(0, haut))
+	case bas > l.scroll.Offset.Y+l.scroll.Size().Height:
+		l.scroll.ScrollToOffset(fyne.NewPos(0, bas-l.scroll.Size().Height))
+	}
+}
+
+func (l *listeSuggestions) choisir(s models.SuggestionRecherche) {
+	l.entry.CacherSuggestions()
+	if l.entry.OnSelection != nil {
+		l.entry.OnSelection(s)
+	}
+}
+
+// deplacer - la selection monte (-1) ou descend (+1), sans sortir de la liste
+func (l *listeSuggestions) deplacer(delta int) {
+	if nouvelle := l.selection + delta; nouvelle >= 0 && nouvelle < len(l.suggestions) {
+		l.selection = nouvelle
+		l.majSelection()
+	}
+}
+
+// choisirSelection - ouvre la suggestion selectionnee, faux s'il y en a pas
+func (l *listeSuggestions) choisirSelection() bool {
+	if l.selection < 0 || l.selection >= len(l.suggestions) {
+		return false
+	}
+	l.choisir(l.suggestions[l.selection])
+	return true
+}
+
+// genererSuggestions - genere les suggestions basees sur le texte tape
+// gere les cas: nom d'artiste, membres, locations, dates (1er album, creation, concerts)
+// tout passe par l'index construit au chargement: tolerant aux fautes, trie par pertinence,
+// et on coupe a 15 seulement apres le tri
+func genererSuggestions(texte string, ix *index.Index) []models.SuggestionRecherche {
+	resultats := ix.Chercher(texte)
+
+	// un lieu apparait une fois par artiste dans l'index, mais on le suggere une seule fois
+	// (sa page liste tous les artistes qui y ont joue)
+	var suggestions []models.SuggestionRecherche
+	positionLieu := make(map[string]int)
+	nbArtistes := make(map[string]int)
+	for _, r := range resultats {
+		s := r.Suggestion
+		if s.Type == index.TypeLieu {
+			cle := geo.CleLieu(s.Cle)
+			nbArtistes[cle]++
+			if _, ok := positionLieu[cle]; ok {
+				continue
 			}
+			positionLieu[cle] = len(suggestions)
+		}
+		suggestions = append(suggestions, s)
+	}
+	for cle, i := range positionLieu {
+		if nb := nbArtistes[cle]; nb > 1 {
+			lieuPropre, _, _ := strings.Cut(suggestions[i].Texte, " → ")
+			suggestions[i].Texte = fmt.Sprintf("%s → location (%d artistes)", lieuPropre, nb)
 		}
 	}
 
@@ -115,23 +301,39 @@ func genererSuggestions(texte string, artistes []models.Artiste, locData models.
 	if len(suggestions) > 15 {
 		suggestions = suggestions[:15]
 	}
-
 	return suggestions
 }
 
-// creerPanneauSuggestions - cree le panneau d'affichage des suggestions
-func creerPanneauSuggestions(suggestions []models.SuggestionRecherche, onSelect func(models.SuggestionRecherche)) *fyne.Container {
-	items := make([]fyne.CanvasObject, 0, len(suggestions))
+// genererSuggestionsRequete - les suggestions pour ce qu'il y a dans la barre de recherche
+// si c'est du texte libre on garde les suggestions classiques, sinon (member:..., concerts>10...)
+// on propose directement les artistes qui matchent la requete
+// le texte libre passe par l'index dans les deux cas, comme pour la grille
+func genererSuggestionsRequete(texte string, artistes []models.Artiste, fiche func(id int) recherche.Fiche, ix *index.Index) []models.SuggestionRecherche {
+	n, err := recherche.Analyser(texte)
+	if err != nil || n == nil {
+		return nil
+	}
+	if libre, ok := recherche.TexteLibre(n); ok {
+		return genererSuggestions(libre, ix)
+	}
 
-	for _, s := range suggestions {
-		suggestion := s // capture pour la closure, sinon bug classique
-		btn := widget.NewButton(suggestion.Texte, func() {
-			onSelect(suggestion)
-		})
-		btn.Importance = widget.LowImportance
-		btn.Alignment = widget.ButtonAlignLeading
-		items = append(items, btn)
+	predicat, err := recherche.CompilerAvec(texte, func(t string) map[int]bool { return ix.ArtistesPour(t) })
+	if err != nil {
+		return nil
 	}
 
-	return container.NewVBox(items...)
+	var suggestions []models.SuggestionRecherche
+	for _, artiste := range artistes {
+		if predicat(fiche(artiste.ID)) {
+			suggestions = append(suggestions, models.SuggestionRecherche{
+				Texte:     artiste.Nom + " → " + index.TypeArtiste,
+				Type:      index.TypeArtiste,
+				ArtisteID: artiste.ID,
+			})
+		}
+		if len(suggestions) == 15 {
+			break
+		}
+	}
+	return suggestions
 }
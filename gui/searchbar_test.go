@@ -0,0 +1,137 @@
+package gui
+
+import (
+	"fmt"
+	"testing"
+
+	"groupie-tracker/index"
+	"groupie-tracker/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+)
+
+// searchbar_test.go - les suggestions sont triees par pertinence avant d'etre coupees a 15,
+// et la barre garde le focus quand elles s'affichent (les fleches et Entree passent par elle)
+
+func TestGenererSuggestionsFautesDeFrappe(t *testing.T) {
+	artistes := []models.Artiste{
+		{ID: 1, Nom: "Pink", DateCreation: 1995},
+		{ID: 2, Nom: "Pink Floyd", DateCreation: 1965},
+		{ID: 3, Nom: "Beyoncé", DateCreation: 1997},
+		{ID: 4, Nom: "Bee Gees", DateCreation: 1958},
+	}
+	ix := index.Construire(artistes, models.IndexLocations{}, models.IndexRelations{})
+
+	cas := []struct {
+		saisie  string
+		premier int
+	}{
+		{"pink floid", 2},
+		{"beyonse", 3},
+		{"PINK FLOYD", 2},
+	}
+	for _, c := range cas {
+		suggestions := genererSuggestions(c.saisie, ix)
+		if len(suggestions) == 0 || suggestions[0].ArtisteID != c.premier {
+			t.Errorf("%q: suggestions %v, attendu l'artiste %d en premier", c.saisie, suggestions, c.premier)
+		}
+	}
+}
+
+func TestGenererSuggestionsCoupeApresTri(t *testing.T) {
+	// 30 artistes qui contiennent juste "queen", puis les bons tout a la fin de l'API
+	var artistes []models.Artiste
+	for id := 1; id <= 30; id++ {
+		artistes = append(artistes, models.Artiste{ID: id, Nom: fmt.Sprintf("Xqueenx %d", id), DateCreation: 1980})
+	}
+	artistes = append(artistes,
+		models.Artiste{ID: 100, Nom: "Queens of the Stone Age", DateCreation: 1996},
+		models.Artiste{ID: 101, Nom: "Queen", DateCreation: 1970},
+	)
+	ix := index.Construire(artistes, models.IndexLocations{}, models.IndexRelations{})
+
+	suggestions := genererSuggestions("queen", ix)
+	if len(suggestions) != 15 {
+		t.Fatalf("%d suggestions, attendu 15", len(suggestions))
+	}
+	if suggestions[0].ArtisteID != 101 || suggestions[1].ArtisteID != 100 {
+		t.Errorf("les deux premieres suggestions sont %v et %v, attendu Queen puis Queens of the Stone Age",
+			suggestions[0].Texte, suggestions[1].Texte)
+	}
+	// et a pertinence egale on garde l'ordre de l'API
+	for i := 2; i < len(suggestions); i++ {
+		if suggestions[i].ArtisteID != i-1 {
+			t.Errorf("suggestion %d: %s, attendu l'artiste %d", i, suggestions[i].Texte, i-1)
+		}
+	}
+}
+
+func TestEntryRechercheGardeLeFocus(t *testing.T) {
+	test.NewTempApp(t)
+	e := NewEntryRecherche()
+	fenetre := test.NewWindow(container.NewStack(container.NewVBox(e), e.Calque()))
+	fenetre.Resize(fyne.NewSize(400, 400))
+	t.Cleanup(fenetre.Close)
+
+	var choisie models.SuggestionRecherche
+	e.OnSelection = func(s models.SuggestionRecherche) { choisie = s }
+	suggestions := []models.SuggestionRecherche{
+		{Texte: "Queen → " + index.TypeArtiste, Type: index.TypeArtiste, ArtisteID: 1},
+		{Texte: "Queens of the Stone Age → " + index.TypeArtiste, Type: index.TypeArtiste, ArtisteID: 2},
+		{Texte: "Queensland → " + index.TypeLieu, Type: index.TypeLieu, ArtisteID: 3},
+	}
+
+	fenetre.Canvas().Focus(e)
+	test.Type(e, "que")
+	e.AfficherSuggestions(suggestions)
+	if !e.SuggestionsVisibles() {
+		t.Fatal("les suggestions s'affichent pas")
+	}
+	if fenetre.Canvas().Focused() != e {
+		t.Fatalf("le focus est sur %T, attendu la barre", fenetre.Canvas().Focused())
+	}
+
+	// la frappe continue d'aller dans la barre
+	test.Type(fenetre.Canvas().Focused(), "e")
+	if e.Text != "quee" {
+		t.Errorf("texte = %q, attendu %q", e.Text, "quee")
+	}
+
+	// les fleches bougent la selection sans sortir de la liste
+	for _, touche := range []fyne.KeyName{fyne.KeyDown, fyne.KeyDown, fyne.KeyDown, fyne.KeyUp} {
+		e.TypedKey(&fyne.KeyEvent{Name: touche})
+	}
+	if e.liste.selection != 1 {
+		t.Errorf("selection = %d, attendu 1", e.liste.selection)
+	}
+
+	// Entree ouvre la suggestion selectionnee et ferme la liste
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if choisie.ArtisteID != 2 || e.SuggestionsVisibles() {
+		t.Errorf("Entree: choisie %v, visibles %v", choisie, e.SuggestionsVisibles())
+	}
+
+	// Echap ferme, et le focus est toujours sur la barre
+	e.AfficherSuggestions(suggestions)
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEscape})
+	if e.SuggestionsVisibles() || fenetre.Canvas().Focused() != e {
+		t.Errorf("Echap: visibles %v, focus sur %T", e.SuggestionsVisibles(), fenetre.Canvas().Focused())
+	}
+
+	// liste fermee: Entree c'est la validation normale de la barre
+	soumis := ""
+	e.OnSubmitted = func(s string) { soumis = s }
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if soumis != "quee" {
+		t.Errorf("OnSubmitted(%q), attendu %q", soumis, "quee")
+	}
+
+	// le focus qui part ferme la liste
+	e.AfficherSuggestions(suggestions)
+	fenetre.Canvas().Unfocus()
+	if e.SuggestionsVisibles() {
+		t.Error("les suggestions restent ouvertes quand la barre perd le focus")
+	}
+}
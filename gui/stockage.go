@@ -0,0 +1,89 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// stockage.go - les petits helpers pour sauvegarder des trucs en local
+// on met tout en JSON dans les preferences Fyne (ca survit a la fermeture de l'app)
+// et on peut exporter / importer en fichier JSON pour partager avec les autres
+
+// chargerPrefJSON - lit une valeur JSON stockee dans les preferences sous une cle
+// si y'a rien ou que c'est casse on laisse la cible telle quelle
+func chargerPrefJSON(prefs fyne.Preferences, cle string, cible interface{}) {
+	brut := prefs.String(cle)
+	if brut == "" {
+		return
+	}
+	if err := json.Unmarshal([]byte(brut), cible); err != nil {
+		fmt.Printf("Warning: preference '%s' illisible: %v\n", cle, err)
+	}
+}
+
+// sauverPrefJSON - ecrit une valeur en JSON dans les preferences
+func sauverPrefJSON(prefs fyne.Preferences, cle string, valeur interface{}) {
+	data, err := json.Marshal(valeur)
+	if err != nil {
+		fmt.Printf("Warning: impossible de sauver la preference '%s': %v\n", cle, err)
+		return
+	}
+	prefs.SetString(cle, string(data))
+}
+
+// exporterJSON - ouvre un dialogue pour enregistrer une valeur dans un fichier JSON
+func exporterJSON(fenetre fyne.Window, nomFichier string, valeur interface{}) {
+	dlg := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, fenetre)
+			return
+		}
+		if writer == nil {
+			return // l'utilisateur a annule
+		}
+		defer writer.Close()
+
+		data, err := json.MarshalIndent(valeur, "", "  ")
+		if err == nil {
+			_, err = writer.Write(data)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("erreur export vers %s: %w", writer.URI().Name(), err), fenetre)
+		}
+	}, fenetre)
+	dlg.SetFileName(nomFichier)
+	dlg.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	dlg.Show()
+}
+
+// importerJSON - ouvre un dialogue pour lire un fichier JSON dans la cible
+// onImporte est appele seulement si la lecture a marche
+func importerJSON(fenetre fyne.Window, cible interface{}, onImporte func()) {
+	dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, fenetre)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err == nil {
+			err = json.Unmarshal(data, cible)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("erreur import depuis %s: %w", reader.URI().Name(), err), fenetre)
+			return
+		}
+		onImporte()
+	}, fenetre)
+	dlg.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	dlg.Show()
+}
@@ -0,0 +1,198 @@
+package gui
+
+import (
+	"sort"
+
+	"groupie-tracker/models"
+	"groupie-tracker/texte"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tri.go - le tri de la grille d'accueil (par nom, date de creation, nb de concerts...)
+// le choix est sauve dans les preferences, on le retrouve au prochain lancement
+
+// la cle dans les preferences Fyne
+const clePrefTri = "tri"
+
+// les criteres de tri
+const (
+	triAPI            = ""
+	triNom            = "nom"
+	triCreation       = "creation"
+	triAlbum          = "album"
+	triMembres        = "membres"
+	triConcerts       = "concerts"
+	triDernierConcert = "dernier_concert"
+	triFavoris        = "favoris"
+	triEtoiles        = "etoiles"
+)
+
+// criteresTri - les criteres dans l'ordre du menu, avec leur libelle
+var criteresTri = []struct {
+	critere, libelle string
+}{
+	{triAPI, "Ordre par défaut"},
+	{triNom, "Nom"},
+	{triCreation, "Année de création"},
+	{triAlbum, "Premier album"},
+	{triMembres, "Nombre de membres"},
+	{triConcerts, "Nombre de concerts"},
+	{triDernierConcert, "Concert le plus récent"},
+	{triFavoris, "Favoris d'abord"},
+	{triEtoiles, "Ma note"},
+}
+
+// Tri - le critere choisi et le sens
+type Tri struct {
+	Critere    string `json:"critere"`
+	Descendant bool   `json:"descendant"`
+}
+
+// chargerTri - lit le tri sauve (l'ordre de l'API si y'a rien)
+func (a *AppGroupie) chargerTri() Tri {
+	var tri Tri
+	chargerPrefJSON(a.app.Preferences(), clePrefTri, &tri)
+	return tri
+}
+
+// sauverTri - ecrit le tri dans les preferences
+func (a *AppGroupie) sauverTri(tri Tri) {
+	sauverPrefJSON(a.app.Preferences(), clePrefTri, tri)
+}
+
+// cleTri - ce qu'on compare pour un artiste: un nombre, ou un texte pour le nom
+type cleTri struct {
+	nombre int64
+	texte  string
+}
+
+// cleTriArtiste - calcule la cle d'un artiste pour le critere
+func (a *AppGroupie) cleTriArtiste(art models.Artiste, critere string) cleTri {
+	switch critere {
+	case triNom:
+		return cleTri{texte: texte.Normaliser(art.Nom)}
+	case triCreation:
+		return cleTri{nombre: int64(art.DateCreation)}
+	case triAlbum:
+		return cleTri{nombre: parserDate(art.PremierAlbum).Unix()}
+	case triMembres:
+		return cleTri{nombre: int64(len(art.Membres))}
+	case triConcerts:
+		return cleTri{nombre: int64(a.fiches[art.ID].NbConcerts())}
+	case triDernierConcert:
+		var dernier int64
+		for _, dates := range a.fiches[art.ID].Concerts {
+			for _, d := range dates {
+				if t := parserDate(d).Unix(); t > dernier {
+					dernier = t
+				}
+			}
+		}
+		return cleTri{nombre: dernier}
+	case triFavoris:
+		// les favoris avant les autres, donc plus petits
+		if a.estFavori(art.ID) {
+			return cleTri{nombre: 0}
+		}
+		return cleTri{nombre: 1}
+	case triEtoiles:
+		return cleTri{nombre: int64(a.noteArtiste(art.ID).Etoiles)}
+	}
+	return cleTri{}
+}
+
+// trierArtistes - renvoie une copie triee, le tri est stable: a egalite on garde l'ordre de l'API
+// (en descendant aussi, on inverse seulement la comparaison)
+func (a *AppGroupie) trierArtistes(artistes []models.Artiste, tri Tri) []models.Artiste {
+	if tri.Critere == triAPI {
+		if tri.Descendant {
+			inverses := make([]models.Artiste, len(artistes))
+			for i, art := range artistes {
+				inverses[len(artistes)-1-i] = art
+			}
+			return inverses
+		}
+		return artistes
+	}
+
+	// les cles sont calculees une seule fois, pas a chaque comparaison
+	type element struct {
+		artiste models.Artiste
+		cle     cleTri
+	}
+	elements := make([]element, len(artistes))
+	for i, art := range artistes {
+		elements[i] = element{art, a.cleTriArtiste(art, tri.Critere)}
+	}
+
+	sort.SliceStable(elements, func(i, j int) bool {
+		ci, cj := elements[i].cle, elements[j].cle
+		if tri.Descendant {
+			ci, cj = cj, ci
+		}
+		if ci.texte != cj.texte {
+			return ci.texte < cj.texte
+		}
+		return ci.nombre < cj.nombre
+	})
+
+	tries := make([]models.Artiste, len(elements))
+	for i, e := range elements {
+		tries[i] = e.artiste
+	}
+	return tries
+}
+
+// creerBarreTri - le menu des criteres et le bouton pour inverser le sens
+// onChange est appele apres chaque changement (le tri est deja sauve)
+func (a *AppGroupie) creerBarreTri(onChange func(Tri)) fyne.CanvasObject {
+	tri := a.chargerTri()
+
+	libelles := make([]string, len(criteresTri))
+	for i, c := range criteresTri {
+		libelles[i] = c.libelle
+	}
+
+	var btnSens *widget.Button
+	majSens := func() {
+		if tri.Descendant {
+			btnSens.SetIcon(theme.MenuDropDownIcon())
+			btnSens.SetText("Décroissant")
+		} else {
+			btnSens.SetIcon(theme.MenuDropUpIcon())
+			btnSens.SetText("Croissant")
+		}
+	}
+	changer := func() {
+		a.sauverTri(tri)
+		onChange(tri)
+	}
+
+	selectTri := widget.NewSelect(libelles, func(libelle string) {
+		for _, c := range criteresTri {
+			if c.libelle == libelle && c.critere != tri.Critere {
+				tri.Critere = c.critere
+				changer()
+				return
+			}
+		}
+	})
+	for _, c := range criteresTri {
+		if c.critere == tri.Critere {
+			selectTri.SetSelected(c.libelle)
+		}
+	}
+
+	btnSens = widget.NewButton("", func() {
+		tri.Descendant = !tri.Descendant
+		majSens()
+		changer()
+	})
+	majSens()
+
+	return container.NewHBox(widget.NewLabel("Trier par"), selectTri, btnSens)
+}
@@ -0,0 +1,228 @@
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// cache.go - le cache des images d'artistes, en memoire et sur le disque
+// avant on retelechargeait toutes les images a chaque lancement et on les gardait
+// toutes en entier en memoire; maintenant:
+//   - sur le disque, chaque image est rangee sous le hash de son contenu (objets/<hash>)
+//     et chaque URL pointe vers son hash (urls/<hash de l'url>), deux URLs avec la meme
+//     image partagent donc le meme fichier et la meme miniature
+//   - en memoire, un LRU limite en octets garde les images et les miniatures recentes
+//   - le disque aussi a sa limite, les images les plus vieilles partent (voir disque.go)
+
+// TelechargerFunc - ce qui va chercher une image sur le reseau
+type TelechargerFunc func(ctx context.Context, url string) ([]byte, error)
+
+// Cache - le cache complet, sur de l'utiliser depuis plusieurs goroutines
+type Cache struct {
+	dossier     string // vide si on a pas de dossier, on garde juste la memoire
+	memoire     *lru
+	telecharger TelechargerFunc
+	envol       enVol         // les telechargements en cours, pour pas les faire deux fois
+	places      chan struct{} // une place par telechargement simultane autorise
+
+	maxDisque    int64        // la taille max du dossier en octets
+	octetsDisque atomic.Int64 // la taille du dossier, a peu pres (les URLs comptent pas)
+	nettoyage    sync.Mutex   // un seul menage a la fois
+}
+
+// prefixe des miniatures dans le LRU (les images entieres sont juste sous leur URL)
+const prefixeMiniature = "mini:"
+
+// NouveauCache - dossier peut etre vide (ou pas creable), le cache marche alors qu'en memoire
+// maxOctets limite la memoire, maxDisque le dossier (le menage est fait tout de suite)
+// maxTelechargements limite les telechargements en meme temps, pour toute l'app
+// (prechargement et grille compris), histoire de pas inonder le serveur
+func NouveauCache(dossier string, maxOctets, maxDisque int64, maxTelechargements int, telecharger TelechargerFunc) *Cache {
+	if dossier != "" {
+		for _, sous := range []string{"urls", "objets"} {
+			if err := os.MkdirAll(filepath.Join(dossier, sous), 0o755); err != nil {
+				fmt.Println("Warning: pas de cache disque pour les images:", err)
+				dossier = ""
+				break
+			}
+		}
+	}
+	c := &Cache{
+		dossier:     dossier,
+		memoire:     nouveauLRU(maxOctets),
+		telecharger: telecharger,
+		places:      make(chan struct{}, max(maxTelechargements, 1)),
+		maxDisque:   maxDisque,
+	}
+	c.nettoyerDisque(true)
+	return c
+}
+
+// DossierParDefaut - le dossier de cache de l'utilisateur (~/.cache/groupie-tracker/images sous Linux)
+func DossierParDefaut() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "groupie-tracker", "images")
+}
+
+// Image - l'image entiere: memoire, puis disque, puis reseau
+func (c *Cache) Image(ctx context.Context, url string) ([]byte, error) {
+	if data, ok := c.memoire.lire(url); ok {
+		return data, nil
+	}
+
+	if hash, ok := c.lireHash(url); ok {
+		if data, err := os.ReadFile(c.cheminObjet(hash)); err == nil {
+			toucher(c.cheminObjet(hash))
+			c.memoire.ecrire(url, data)
+			return data, nil
+		}
+	}
+
+	return c.envol.faire(ctx, url, func(ctx context.Context) ([]byte, error) {
+		// on attend une place libre avant de telecharger
+		select {
+		case c.places <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() { <-c.places }()
+
+		data, err := c.telecharger(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		c.memoire.ecrire(url, data)
+		c.ecrireDisque(url, data)
+		return data, nil
+	})
+}
+
+// Miniature - l'image reduite pour les cards, calculee une seule fois par contenu
+// si l'image se decode pas on renvoie l'image entiere, Fyne s'en sortira peut etre
+func (c *Cache) Miniature(ctx context.Context, url string) ([]byte, error) {
+	cle := prefixeMiniature + url
+	if data, ok := c.memoire.lire(cle); ok {
+		return data, nil
+	}
+
+	hash, ok := c.lireHash(url)
+	if ok {
+		if data, err := os.ReadFile(c.cheminMiniature(hash)); err == nil {
+			toucher(c.cheminMiniature(hash))
+			c.memoire.ecrire(cle, data)
+			return data, nil
+		}
+	}
+
+	entiere, err := c.Image(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	mini, err := Reduire(entiere, TailleMiniature)
+	if err != nil {
+		fmt.Println("Warning: miniature impossible pour", url, ":", err)
+		return entiere, nil
+	}
+	c.memoire.ecrire(cle, mini)
+	if !ok {
+		hash = hashContenu(entiere)
+	}
+	if c.ecrireFichier(c.cheminMiniature(hash), mini) {
+		c.ajouteSurDisque(len(mini))
+	}
+	return mini, nil
+}
+
+// EnMemoire - l'image entiere est deja en memoire (pas besoin d'attendre pour l'afficher)
+func (c *Cache) EnMemoire(url string) bool {
+	return c.memoire.contient(url)
+}
+
+// MiniatureEnMemoire - pareil pour la miniature
+func (c *Cache) MiniatureEnMemoire(url string) bool {
+	return c.memoire.contient(prefixeMiniature + url)
+}
+
+// hashContenu - le sha256 en hexa
+func hashContenu(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func (c *Cache) cheminURL(url string) string {
+	return filepath.Join(c.dossier, "urls", hashContenu([]byte(url)))
+}
+
+func (c *Cache) cheminObjet(hash string) string {
+	return filepath.Join(c.dossier, "objets", hash)
+}
+
+func (c *Cache) cheminMiniature(hash string) string {
+	return filepath.Join(c.dossier, "objets", hash+suffixeMiniature)
+}
+
+// lireHash - le hash du contenu de l'URL si on l'a deja telechargee
+func (c *Cache) lireHash(url string) (string, bool) {
+	if c.dossier == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(c.cheminURL(url))
+	if err != nil {
+		return "", false
+	}
+	hash := strings.TrimSpace(string(data))
+	return hash, hash != ""
+}
+
+// ecrireDisque - range l'image sous son hash et fait pointer l'URL dessus
+func (c *Cache) ecrireDisque(url string, data []byte) {
+	if c.dossier == "" {
+		return
+	}
+	hash := hashContenu(data)
+	nouveau := false
+	if _, err := os.Stat(c.cheminObjet(hash)); err != nil {
+		nouveau = c.ecrireFichier(c.cheminObjet(hash), data)
+	}
+	c.ecrireFichier(c.cheminURL(url), []byte(hash))
+	// compte apres avoir ecrit l'URL, sinon le menage prendrait l'objet pour un orphelin
+	if nouveau {
+		c.ajouteSurDisque(len(data))
+	}
+}
+
+// ecrireFichier - ecrit dans un fichier temporaire puis le renomme,
+// comme ca un crash au milieu laisse jamais une image a moitie ecrite; renvoie si c'est ecrit
+func (c *Cache) ecrireFichier(chemin string, data []byte) bool {
+	if c.dossier == "" {
+		return false
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(chemin), ".tmp-*")
+	if err != nil {
+		fmt.Println("Warning: cache image pas ecrit:", err)
+		return false
+	}
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), chemin)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		fmt.Println("Warning: cache image pas ecrit:", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cache_test.go - la limite du cache sur le disque: le menage au lancement et les plus vieilles
+// images jetees quand on depasse (pas de reseau, le telechargement renvoie des octets fabriques)
+
+// faux - un telechargement qui compte les appels, 100 octets differents par URL
+type faux struct {
+	mu     sync.Mutex
+	appels map[string]int
+}
+
+func (f *faux) telecharger(_ context.Context, url string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.appels == nil {
+		f.appels = make(map[string]int)
+	}
+	f.appels[url]++
+	return bytes.Repeat([]byte(url[len(url)-1:]), 100), nil
+}
+
+// nouveauCacheTest - maxOctets a 1: rien reste en memoire, chaque lecture passe par le disque
+func nouveauCacheTest(dossier string, maxDisque int64, f *faux) *Cache {
+	return NouveauCache(dossier, 1, maxDisque, 1, f.telecharger)
+}
+
+// vieillir - recule la date de l'objet de l'URL
+func vieillir(t *testing.T, c *Cache, url string, de time.Duration) {
+	t.Helper()
+	hash, ok := c.lireHash(url)
+	if !ok {
+		t.Fatalf("%s pas sur le disque", url)
+	}
+	date := time.Now().Add(-de)
+	if err := os.Chtimes(c.cheminObjet(hash), date, date); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimiteDisque(t *testing.T) {
+	ctx := context.Background()
+	f := &faux{}
+	c := nouveauCacheTest(t.TempDir(), 250, f)
+
+	for _, url := range []string{"http://a", "http://b"} {
+		if _, err := c.Image(ctx, url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vieillir(t, c, "http://a", 2*time.Hour)
+	vieillir(t, c, "http://b", time.Hour)
+	// a est relue, c'est b la plus vieille maintenant
+	c.Image(ctx, "http://a")
+
+	// la troisieme depasse les 250 octets: b part, a et c restent
+	c.Image(ctx, "http://c")
+	if c.octetsDisque.Load() > 250 {
+		t.Errorf("%d octets sur le disque, limite 250", c.octetsDisque.Load())
+	}
+	for url, garde := range map[string]bool{"http://a": true, "http://b": false, "http://c": true} {
+		if _, ok := c.lireHash(url); ok != garde {
+			t.Errorf("%s sur le disque: %v, attendu %v", url, ok, garde)
+		}
+	}
+
+	// b se retelecharge, a se relit du disque
+	c.Image(ctx, "http://a")
+	c.Image(ctx, "http://b")
+	if f.appels["http://a"] != 1 || f.appels["http://b"] != 2 {
+		t.Errorf("telechargements = %v, attendu b retelechargee seulement", f.appels)
+	}
+}
+
+func TestNettoyageAuLancement(t *testing.T) {
+	ctx := context.Background()
+	dossier := t.TempDir()
+	f := &faux{}
+	c := nouveauCacheTest(dossier, 1<<20, f)
+	for _, url := range []string{"http://a", "http://b", "http://c"} {
+		c.Image(ctx, url)
+	}
+	vieillir(t, c, "http://a", 2*time.Hour)
+	vieillir(t, c, "http://b", time.Hour)
+
+	// ce qu'un vieux lancement ou un crash laisse trainer
+	orphelin := filepath.Join(dossier, "objets", hashContenu([]byte("plus pointe")))
+	dansLeVide := filepath.Join(dossier, "urls", hashContenu([]byte("http://disparue")))
+	temporaire := filepath.Join(dossier, "objets", ".tmp-123")
+	for chemin, data := range map[string]string{orphelin: "xxxx", dansLeVide: hashContenu([]byte("rien")), temporaire: "x"} {
+		if err := os.WriteFile(chemin, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// au lancement avec une limite plus petite: le menage, puis on garde les plus recentes
+	c = nouveauCacheTest(dossier, 150, f)
+	for _, chemin := range []string{orphelin, dansLeVide, temporaire} {
+		if _, err := os.Stat(chemin); err == nil {
+			t.Errorf("%s pas supprime", filepath.Base(chemin))
+		}
+	}
+	if _, ok := c.lireHash("http://c"); !ok {
+		t.Error("la plus recente est partie")
+	}
+	for _, url := range []string{"http://a", "http://b"} {
+		if _, ok := c.lireHash(url); ok {
+			t.Errorf("%s toujours sur le disque, limite depassee", url)
+		}
+	}
+	if c.octetsDisque.Load() != 100 {
+		t.Errorf("%d octets sur le disque, attendu 100", c.octetsDisque.Load())
+	}
+}
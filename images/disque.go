@@ -0,0 +1,132 @@
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// disque.go - la limite de taille du cache sur le disque
+// sans ca objets/ grossit a chaque image jamais revue (artiste retire de l'API, image changee...)
+//   - au lancement on jette ce qui sert plus: les objets qu'aucune URL pointe, les URLs
+//     qui pointent sur un objet disparu, les fichiers temporaires d'un crash
+//   - si on depasse la limite (au lancement ou en cours de route), on jette les objets les
+//     plus vieux (date de modif, remise a jour a chaque lecture) avec leur miniature et leurs URLs
+
+// suffixeMiniature - la miniature est a cote de l'objet, sous le meme hash
+const suffixeMiniature = ".mini.jpg"
+
+// objetDisque - une image sur le disque: l'objet, sa miniature et les URLs qui pointent dessus
+type objetDisque struct {
+	fichiers []string
+	urls     []string
+	taille   int64
+	modif    time.Time // la plus recente des dates de l'objet et de sa miniature
+}
+
+// nettoyerDisque - ramene le dossier sous la limite, et au lancement fait aussi le menage
+// (en cours de route un objet sans URL c'est peut etre une image en train d'etre ecrite)
+func (c *Cache) nettoyerDisque(auLancement bool) {
+	if c.dossier == "" {
+		return
+	}
+	c.nettoyage.Lock()
+	defer c.nettoyage.Unlock()
+
+	objets := make(map[string]*objetDisque)
+	fichiers, err := os.ReadDir(filepath.Join(c.dossier, "objets"))
+	if err != nil {
+		fmt.Println("Warning: cache image pas nettoye:", err)
+		return
+	}
+	for _, f := range fichiers {
+		chemin := filepath.Join(c.dossier, "objets", f.Name())
+		if strings.HasPrefix(f.Name(), ".tmp-") {
+			if auLancement {
+				os.Remove(chemin)
+			}
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		hash := strings.TrimSuffix(f.Name(), suffixeMiniature)
+		o := objets[hash]
+		if o == nil {
+			o = &objetDisque{}
+			objets[hash] = o
+		}
+		o.fichiers = append(o.fichiers, chemin)
+		o.taille += info.Size()
+		if info.ModTime().After(o.modif) {
+			o.modif = info.ModTime()
+		}
+	}
+
+	// les URLs: celles qui pointent dans le vide partent, les autres rattachent leur objet
+	urls, _ := os.ReadDir(filepath.Join(c.dossier, "urls"))
+	for _, f := range urls {
+		chemin := filepath.Join(c.dossier, "urls", f.Name())
+		data, err := os.ReadFile(chemin)
+		o := objets[strings.TrimSpace(string(data))]
+		if err != nil || o == nil || strings.HasPrefix(f.Name(), ".tmp-") {
+			if auLancement {
+				os.Remove(chemin)
+			}
+			continue
+		}
+		o.urls = append(o.urls, chemin)
+	}
+
+	// les objets orphelins partent, le reste compte dans la taille
+	var gardes []*objetDisque
+	var total int64
+	for _, o := range objets {
+		if len(o.urls) == 0 && auLancement {
+			supprimerObjet(o)
+			continue
+		}
+		total += o.taille
+		if len(o.urls) > 0 {
+			gardes = append(gardes, o)
+		}
+	}
+
+	// au dessus de la limite on jette les plus vieux d'abord
+	sort.Slice(gardes, func(i, j int) bool { return gardes[i].modif.Before(gardes[j].modif) })
+	for _, o := range gardes {
+		if total <= c.maxDisque {
+			break
+		}
+		supprimerObjet(o)
+		total -= o.taille
+	}
+	c.octetsDisque.Store(total)
+}
+
+// supprimerObjet - les URLs d'abord, comme ca aucune URL pointe vers un objet a moitie supprime
+func supprimerObjet(o *objetDisque) {
+	for _, chemin := range o.urls {
+		os.Remove(chemin)
+	}
+	for _, chemin := range o.fichiers {
+		os.Remove(chemin)
+	}
+}
+
+// ajouteSurDisque - compte un fichier ecrit et fait le menage si on depasse la limite
+func (c *Cache) ajouteSurDisque(octets int) {
+	if c.octetsDisque.Add(int64(octets)) > c.maxDisque {
+		c.nettoyerDisque(false)
+	}
+}
+
+// toucher - une image relue est recente, c'est pas elle qu'on jettera en premier
+func toucher(chemin string) {
+	maintenant := time.Now()
+	os.Chtimes(chemin, maintenant, maintenant)
+}
@@ -0,0 +1,71 @@
+package images
+
+import (
+	"context"
+	"sync"
+)
+
+// envol.go - les telechargements en cours, partages entre tous ceux qui veulent la meme image
+// sans ca le prechargement et une card de la grille pouvaient telecharger la meme image
+// en meme temps; maintenant le deuxieme attend juste le resultat du premier
+// le telechargement est abandonne seulement quand plus personne l'attend
+
+// appelEnVol - un telechargement en cours
+type appelEnVol struct {
+	fini    chan struct{} // ferme quand data et err sont remplis
+	data    []byte
+	err     error
+	attente int // le nb d'appelants qui attendent encore
+	annuler context.CancelFunc
+}
+
+// enVol - les telechargements en cours, par URL
+type enVol struct {
+	mu     sync.Mutex
+	appels map[string]*appelEnVol
+}
+
+// faire - lance telecharger pour l'URL, ou se greffe sur le telechargement deja en cours
+func (e *enVol) faire(ctx context.Context, url string, telecharger func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	e.mu.Lock()
+	if e.appels == nil {
+		e.appels = make(map[string]*appelEnVol)
+	}
+	appel, ok := e.appels[url]
+	if !ok {
+		// le telechargement a son propre contexte: il appartient a personne en particulier
+		ctxAppel, annuler := context.WithCancel(context.Background())
+		appel = &appelEnVol{fini: make(chan struct{}), annuler: annuler}
+		e.appels[url] = appel
+		go func() {
+			data, err := telecharger(ctxAppel)
+			e.mu.Lock()
+			appel.data, appel.err = data, err
+			if e.appels[url] == appel {
+				delete(e.appels, url)
+			}
+			e.mu.Unlock()
+			annuler()
+			close(appel.fini)
+		}()
+	}
+	appel.attente++
+	e.mu.Unlock()
+
+	select {
+	case <-appel.fini:
+		return appel.data, appel.err
+	case <-ctx.Done():
+		e.mu.Lock()
+		appel.attente--
+		if appel.attente == 0 {
+			// plus personne veut cette image, le prochain qui la demande repart de zero
+			appel.annuler()
+			if e.appels[url] == appel {
+				delete(e.appels, url)
+			}
+		}
+		e.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
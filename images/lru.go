@@ -0,0 +1,78 @@
+package images
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru.go - le cache en memoire des images, limite en octets
+// quand on depasse la limite on jette les images pas utilisees depuis le plus longtemps
+
+// entreeLRU - une image dans la liste
+type entreeLRU struct {
+	cle  string
+	data []byte
+}
+
+// lru - la liste va de la plus recente (devant) a la plus ancienne (derriere)
+type lru struct {
+	mu       sync.Mutex
+	maxi     int64 // la taille max en octets
+	taille   int64
+	liste    *list.List
+	elements map[string]*list.Element
+}
+
+func nouveauLRU(maxi int64) *lru {
+	return &lru{
+		maxi:     maxi,
+		liste:    list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// lire - renvoie l'image et la remet devant
+func (l *lru) lire(cle string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.elements[cle]
+	if !ok {
+		return nil, false
+	}
+	l.liste.MoveToFront(el)
+	return el.Value.(*entreeLRU).data, true
+}
+
+// contient - comme lire mais sans toucher a l'ordre
+func (l *lru) contient(cle string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.elements[cle]
+	return ok
+}
+
+// ecrire - ajoute (ou remplace) une image, puis fait de la place si besoin
+// une image plus grosse que tout le cache est juste pas gardee
+func (l *lru) ecrire(cle string, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if int64(len(data)) > l.maxi {
+		return
+	}
+	if el, ok := l.elements[cle]; ok {
+		e := el.Value.(*entreeLRU)
+		l.taille += int64(len(data)) - int64(len(e.data))
+		e.data = data
+		l.liste.MoveToFront(el)
+	} else {
+		l.elements[cle] = l.liste.PushFront(&entreeLRU{cle: cle, data: data})
+		l.taille += int64(len(data))
+	}
+	for l.taille > l.maxi {
+		el := l.liste.Back()
+		e := el.Value.(*entreeLRU)
+		l.liste.Remove(el)
+		delete(l.elements, e.cle)
+		l.taille -= int64(len(e.data))
+	}
+}
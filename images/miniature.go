@@ -0,0 +1,75 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+
+	// les formats qu'on sait lire en plus du jpeg
+	_ "image/gif"
+	_ "image/png"
+)
+
+// miniature.go - reduit une image pour les cards de la grille
+// les images de l'API font souvent 1000px et plus alors que la card en affiche 150,
+// les garder en entier pour chaque card ca bouffe de la memoire pour rien
+
+// TailleMiniature - le plus grand cote d'une miniature en pixels
+// (un peu plus que la card pour rester net sur les ecrans HiDPI)
+const TailleMiniature = 300
+
+// qualiteMiniature - la qualite du jpeg des miniatures
+const qualiteMiniature = 85
+
+// Reduire - decode l'image, la reduit pour que son plus grand cote fasse au plus taille
+// et la reencode en jpeg; une image deja assez petite est renvoyee telle quelle
+func Reduire(data []byte, taille int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	l, h := b.Dx(), b.Dy()
+	if l <= taille && h <= taille {
+		return data, nil
+	}
+
+	// on garde les proportions
+	nl, nh := taille, h*taille/l
+	if h > l {
+		nl, nh = l*taille/h, taille
+	}
+	nl, nh = max(nl, 1), max(nh, 1)
+
+	// chaque pixel de la miniature c'est la moyenne du bloc de pixels qu'il recouvre,
+	// c'est plus joli que de prendre un pixel sur n (ca evite l'effet escalier)
+	dst := image.NewRGBA(image.Rect(0, 0, nl, nh))
+	for y := 0; y < nh; y++ {
+		y0 := b.Min.Y + y*h/nh
+		y1 := max(b.Min.Y+(y+1)*h/nh, y0+1)
+		for x := 0; x < nl; x++ {
+			x0 := b.Min.X + x*l/nl
+			x1 := max(b.Min.X+(x+1)*l/nl, x0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: qualiteMiniature}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
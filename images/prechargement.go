@@ -0,0 +1,53 @@
+package images
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// prechargement.go - telecharge toutes les miniatures d'un coup au lancement
+// avant les images venaient une par une quand les cards s'affichaient; maintenant des
+// que la liste des artistes est la on lance plusieurs workers qui remplissent le cache,
+// et la grille trouve ses images deja pretes (ou en cours, elle attend alors la meme)
+
+// ProgressionFunc - appelee apres chaque image (reussie ou pas), depuis les workers
+type ProgressionFunc func(faites, total int)
+
+// Precharger - prepare la miniature de chaque URL avec nbWorkers workers
+// la limite globale du cache s'applique en plus, donc nbWorkers au dessus sert a rien
+// renvoie le nb d'images en erreur; s'arrete proprement si ctx est annule
+func (c *Cache) Precharger(ctx context.Context, urls []string, nbWorkers int, progression ProgressionFunc) int {
+	travail := make(chan string)
+	var faites, erreurs atomic.Int64
+	var wg sync.WaitGroup
+
+	for range max(nbWorkers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range travail {
+				if _, err := c.Miniature(ctx, url); err != nil {
+					erreurs.Add(1)
+				}
+				n := faites.Add(1)
+				if progression != nil {
+					progression(int(n), len(urls))
+				}
+			}
+		}()
+	}
+
+	for _, url := range urls {
+		select {
+		case travail <- url:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(travail)
+	wg.Wait()
+	return int(erreurs.Load())
+}
@@ -0,0 +1,332 @@
+package index
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"groupie-tracker/models"
+	"groupie-tracker/recherche"
+	"groupie-tracker/texte"
+)
+
+// index.go - l'index de recherche inverse, construit une seule fois apres le chargement
+// avant on reparcourait tous les artistes / membres / lieux a chaque frappe en refaisant
+// des ToLower partout; maintenant tout est normalise d'avance et on passe par des postings:
+//   - mot normalise -> les entrees qui contiennent ce mot
+//   - trigramme     -> les entrees qui contiennent ce trigramme (pour les fautes de frappe)
+
+// les types d'entree (les memes textes que les suggestions)
+const (
+	TypeArtiste  = "artist/band"
+	TypeMembre   = "member"
+	TypeAlbum    = "first album date"
+	TypeCreation = "creation date"
+	TypeLieu     = "location"
+	TypeConcert  = "concert date"
+)
+
+// Entree - un texte cherchable qui pointe vers un artiste
+type Entree struct {
+	Suggestion models.SuggestionRecherche // ce qu'on affiche dans les suggestions
+	Valeur     string                     // le texte brut qui est cherche (nom, membre, lieu...)
+	normalise  string
+}
+
+// Resultat - une entree qui matche avec son score de pertinence
+type Resultat struct {
+	Entree
+	Score int
+	ordre int // la position de l'entree dans l'index
+}
+
+// Index - l'index complet
+type Index struct {
+	entrees    []Entree
+	postings   map[string][]int // mot -> indices des entrees
+	mots       []string         // tous les mots connus, tries (le dictionnaire)
+	trigrammes map[string][]int // trigramme -> indices des entrees
+
+	// la derniere recherche, la grille et les suggestions demandent souvent la meme chose
+	cacheMu      sync.Mutex
+	cacheRequete string
+	cacheRes     []Resultat
+}
+
+// Construire - construit l'index a partir de toutes les donnees chargees
+// l'ordre des entrees c'est l'ordre de l'API, il sert a departager les scores egaux
+func Construire(artistes []models.Artiste, locData models.IndexLocations, relData models.IndexRelations) *Index {
+	ix := &Index{
+		postings:   make(map[string][]int),
+		trigrammes: make(map[string][]int),
+	}
+
+	noms := make(map[int]string, len(artistes))
+	for _, artiste := range artistes {
+		noms[artiste.ID] = artiste.Nom
+
+		ix.ajouter(artiste.Nom, artiste.Nom+" → artist/band", TypeArtiste, artiste.ID, "")
+		for _, membre := range artiste.Membres {
+			membre = strings.TrimSpace(membre)
+			ix.ajouter(membre, membre+" → member ("+artiste.Nom+")", TypeMembre, artiste.ID, membre)
+		}
+		ix.ajouter(artiste.PremierAlbum, artiste.PremierAlbum+" → first album date ("+artiste.Nom+")", TypeAlbum, artiste.ID, "")
+		creation := fmt.Sprintf("%d", artiste.DateCreation)
+		ix.ajouter(creation, creation+" → creation date ("+artiste.Nom+")", TypeCreation, artiste.ID, "")
+	}
+
+	nomDe := func(id int) string {
+		if nom, ok := noms[id]; ok {
+			return nom
+		}
+		return "?"
+	}
+
+	// un lieu par artiste, meme s'il y a joue plusieurs fois
+	for _, loc := range locData.Index {
+		dejavu := make(map[string]bool)
+		for _, lieu := range loc.Locations {
+			lieuPropre := strings.ReplaceAll(lieu, "_", " ")
+			lieuPropre = strings.ReplaceAll(lieuPropre, "-", ", ")
+			if dejavu[lieuPropre] {
+				continue
+			}
+			dejavu[lieuPropre] = true
+			ix.ajouter(lieuPropre, lieuPropre+" → location ("+nomDe(loc.ID)+")", TypeLieu, loc.ID, lieu)
+		}
+	}
+
+	// les dates de concert, triees pour que l'ordre soit stable (la relation c'est une map)
+	for _, rel := range relData.Index {
+		dejavu := make(map[string]bool)
+		var dates []string
+		for _, ds := range rel.DatesLocations {
+			for _, d := range ds {
+				d = strings.TrimPrefix(d, "*")
+				if !dejavu[d] {
+					dejavu[d] = true
+					dates = append(dates, d)
+				}
+			}
+		}
+		sort.Strings(dates)
+		for _, d := range dates {
+			ix.ajouter(d, d+" → concert date ("+nomDe(rel.ID)+")", TypeConcert, rel.ID, "")
+		}
+	}
+
+	for mot := range ix.postings {
+		ix.mots = append(ix.mots, mot)
+	}
+	sort.Strings(ix.mots)
+
+	return ix
+}
+
+// ajouter - ajoute une entree et ses postings
+// cle c'est ce qui identifie la page a ouvrir quand ce n'est pas celle de l'artiste (un membre, un lieu)
+func (ix *Index) ajouter(valeur, libelle, typ string, artisteID int, cle string) {
+	normalise := texte.Normaliser(valeur)
+	if normalise == "" {
+		return
+	}
+	idx := len(ix.entrees)
+	ix.entrees = append(ix.entrees, Entree{
+		Suggestion: models.SuggestionRecherche{Texte: libelle, Type: typ, ArtisteID: artisteID, Cle: cle},
+		Valeur:     valeur,
+		normalise:  normalise,
+	})
+
+	dejavu := make(map[string]bool)
+	for _, mot := range texte.Mots(normalise) {
+		if !dejavu[mot] {
+			dejavu[mot] = true
+			ix.postings[mot] = append(ix.postings[mot], idx)
+		}
+	}
+	for tri := range recherche.Trigrammes(normalise) {
+		ix.trigrammes[tri] = append(ix.trigrammes[tri], idx)
+	}
+}
+
+// Taille - le nombre d'entrees dans l'index
+func (ix *Index) Taille() int {
+	return len(ix.entrees)
+}
+
+// candidats - les entrees qui ont une chance de matcher, avant de calculer les scores
+//   - correspondance exacte: chaque mot de la requete est contenu dans un mot de l'entree
+//     (voir entreesDuMot, on ne parcourt jamais tout le dictionnaire)
+//   - fautes de frappe: l'entree partage assez de trigrammes avec la requete
+func (ix *Index) candidats(q string) map[int]bool {
+	res := make(map[int]bool)
+
+	var communs []int
+	for i, motQ := range texte.Mots(q) {
+		trouves := ix.entreesDuMot(motQ)
+		if i == 0 {
+			communs = trouves
+		} else {
+			communs = intersecter(communs, trouves)
+		}
+	}
+	for _, idx := range communs {
+		res[idx] = true
+	}
+
+	// pour les fautes de frappe il faut au moins un tiers des trigrammes en commun
+	trisQ := recherche.Trigrammes(q)
+	if len([]rune(q)) >= 4 {
+		compte := make(map[int]int)
+		for tri := range trisQ {
+			for _, idx := range ix.trigrammes[tri] {
+				compte[idx]++
+			}
+		}
+		seuil := len(trisQ) / 3
+		if seuil < 1 {
+			seuil = 1
+		}
+		for idx, nb := range compte {
+			if nb >= seuil {
+				res[idx] = true
+			}
+		}
+	}
+
+	return res
+}
+
+// entreesDuMot - les entrees (triees) dont un mot contient motQ
+//   - un mot commence par motQ: les mots du dictionnaire qui ont motQ comme prefixe se suivent
+//     dans ix.mots (trie), on trouve le debut de la plage par recherche dichotomique
+//   - motQ plus loin dans un mot: les entrees qui ont tous les trigrammes de motQ (c'est large,
+//     le score verifie apres); en dessous de 3 caracteres y'a pas de trigramme, on s'arrete
+//     aux debuts de mots
+func (ix *Index) entreesDuMot(motQ string) []int {
+	var listes [][]int
+	debut := sort.SearchStrings(ix.mots, motQ)
+	for _, mot := range ix.mots[debut:] {
+		if !strings.HasPrefix(mot, motQ) {
+			break
+		}
+		listes = append(listes, ix.postings[mot])
+	}
+	res := unir(listes)
+
+	runes := []rune(motQ)
+	if len(runes) < 3 {
+		return res
+	}
+	var parTrigramme [][]int
+	for i := 0; i+3 <= len(runes); i++ {
+		postings, ok := ix.trigrammes[string(runes[i:i+3])]
+		if !ok {
+			return res // un trigramme que personne a: aucun mot le contient
+		}
+		parTrigramme = append(parTrigramme, postings)
+	}
+	// on commence par la liste la plus courte, l'intersection ne fait que retrecir
+	sort.Slice(parTrigramme, func(i, j int) bool { return len(parTrigramme[i]) < len(parTrigramme[j]) })
+	contient := parTrigramme[0]
+	for _, postings := range parTrigramme[1:] {
+		contient = intersecter(contient, postings)
+	}
+	return unir([][]int{res, contient})
+}
+
+// intersecter - les indices presents dans les deux listes triees
+func intersecter(a, b []int) []int {
+	var res []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// unir - les indices de toutes les listes, tries et sans doublons
+func unir(listes [][]int) []int {
+	var res []int
+	for _, l := range listes {
+		res = append(res, l...)
+	}
+	sort.Ints(res)
+	return slices.Compact(res)
+}
+
+// Chercher - toutes les entrees qui matchent le texte, triees par pertinence
+// (a score egal, dans l'ordre de l'index)
+func (ix *Index) Chercher(requete string) []Resultat {
+	q := texte.Normaliser(requete)
+	if q == "" {
+		return nil
+	}
+
+	ix.cacheMu.Lock()
+	if q == ix.cacheRequete {
+		res := ix.cacheRes
+		ix.cacheMu.Unlock()
+		return res
+	}
+	ix.cacheMu.Unlock()
+
+	var resultats []Resultat
+	for idx := range ix.candidats(q) {
+		e := ix.entrees[idx]
+		if score := recherche.ScoreNormalise(q, e.normalise); score > 0 {
+			resultats = append(resultats, Resultat{Entree: e, Score: score, ordre: idx})
+		}
+	}
+
+	// les indices viennent d'une map, on retrie par score puis par position dans l'index
+	sort.Slice(resultats, func(i, j int) bool {
+		if resultats[i].Score != resultats[j].Score {
+			return resultats[i].Score > resultats[j].Score
+		}
+		return resultats[i].ordre < resultats[j].ordre
+	})
+
+	ix.cacheMu.Lock()
+	ix.cacheRequete, ix.cacheRes = q, resultats
+	ix.cacheMu.Unlock()
+	return resultats
+}
+
+// ArtistesPour - les IDs des artistes qui ont au moins une entree d'un des types donnes
+// qui matche (tous les types si on en donne aucun)
+func (ix *Index) ArtistesPour(requete string, types ...string) map[int]bool {
+	ok := make(map[string]bool, len(types))
+	for _, t := range types {
+		ok[t] = true
+	}
+	ids := make(map[int]bool)
+	for _, r := range ix.Chercher(requete) {
+		if len(types) == 0 || ok[r.Suggestion.Type] {
+			ids[r.Suggestion.ArtisteID] = true
+		}
+	}
+	return ids
+}
+
+// MeilleurParArtiste - pour chaque artiste, l'entree la plus pertinente qui matche
+// (c'est ce qu'on affiche sur les cards pour dire pourquoi l'artiste est la)
+func (ix *Index) MeilleurParArtiste(requete string) map[int]Resultat {
+	meilleurs := make(map[int]Resultat)
+	for _, r := range ix.Chercher(requete) {
+		if _, ok := meilleurs[r.Suggestion.ArtisteID]; !ok {
+			meilleurs[r.Suggestion.ArtisteID] = r
+		}
+	}
+	return meilleurs
+}
@@ -0,0 +1,186 @@
+package index
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"groupie-tracker/models"
+	"groupie-tracker/texte"
+)
+
+// index_test.go - la latence par frappe sur un jeu 100x plus gros que l'API
+// (l'API donne 52 artistes, ici on en fabrique 5200 avec leurs membres, lieux et concerts)
+// une operation = une frappe, donc ns/op c'est directement la latence par frappe
+// go test -run '^$' -bench . -benchmem ./index
+
+// tailleAPI - le nombre d'artistes de la vraie API
+const tailleAPI = 52
+
+// facteurBench - combien de fois l'API on simule
+const facteurBench = 100
+
+var syllabes = []string{"ka", "lo", "mi", "ra", "to", "ne", "vi", "sa", "du", "pe", "zo", "li", "qua", "ber", "ston", "phil", "ray", "mon"}
+
+var villesBench = []string{
+	"london-uk", "seattle-usa", "sao_paulo-brazil", "osaka-japan", "paris-france", "berlin-germany",
+	"north_carolina-usa", "los_angeles-usa", "mexico_city-mexico", "lodz-poland", "sydney-australia",
+	"dunedin-new_zealand", "lyon-france", "madrid-spain", "rio_de_janeiro-brazil", "toronto-canada",
+	"mumbai-india", "johannesburg-south_africa", "oslo-norway", "glasgow-uk",
+}
+
+// motBench - un mot invente de 2 a 4 syllabes
+func motBench(r *rand.Rand) string {
+	var b strings.Builder
+	for range 2 + r.IntN(3) {
+		b.WriteString(syllabes[r.IntN(len(syllabes))])
+	}
+	mot := b.String()
+	return strings.ToUpper(mot[:1]) + mot[1:]
+}
+
+// jeuBench - un jeu d'artistes synthetique, toujours le meme (graine fixe)
+// avec quelques vrais noms au milieu pour que les requetes du bench trouvent quelque chose
+func jeuBench(nbArtistes int) ([]models.Artiste, models.IndexLocations, models.IndexRelations) {
+	r := rand.New(rand.NewPCG(42, 2024))
+	vrais := []string{"Pink Floyd", "Queen", "Beyoncé", "Phil Collins", "Freddie Mercury", "SOJA"}
+
+	var artistes []models.Artiste
+	var locData models.IndexLocations
+	var relData models.IndexRelations
+	for id := 1; id <= nbArtistes; id++ {
+		nom := motBench(r) + " " + motBench(r)
+		if id%50 == 0 {
+			nom = vrais[(id/50)%len(vrais)] + " " + motBench(r)
+		}
+		var membres []string
+		for range 1 + r.IntN(6) {
+			membres = append(membres, motBench(r)+" "+motBench(r))
+		}
+		artistes = append(artistes, models.Artiste{
+			ID:           id,
+			Nom:          nom,
+			Membres:      membres,
+			DateCreation: 1950 + r.IntN(70),
+			PremierAlbum: fmt.Sprintf("%02d-%02d-%d", 1+r.IntN(28), 1+r.IntN(12), 1960+r.IntN(60)),
+		})
+
+		var lieux []string
+		concerts := make(map[string][]string)
+		for range 3 + r.IntN(10) {
+			lieu := villesBench[r.IntN(len(villesBench))]
+			lieux = append(lieux, lieu)
+			concerts[lieu] = append(concerts[lieu], fmt.Sprintf("%02d-%02d-%d", 1+r.IntN(28), 1+r.IntN(12), 1990+r.IntN(35)))
+		}
+		locData.Index = append(locData.Index, models.LocationData{ID: id, Locations: lieux})
+		relData.Index = append(relData.Index, models.Relation{ID: id, DatesLocations: concerts})
+	}
+	return artistes, locData, relData
+}
+
+// indexBench - l'index du jeu 100x, construit une fois pour tous les benchs
+var indexBench = func() func() *Index {
+	var ix *Index
+	return func() *Index {
+		if ix == nil {
+			ix = Construire(jeuBench(tailleAPI * facteurBench))
+		}
+		return ix
+	}
+}()
+
+// frappes - tous les debuts d'une saisie, comme si on la tapait lettre par lettre
+func frappes(saisies ...string) []string {
+	var res []string
+	for _, s := range saisies {
+		runes := []rune(s)
+		for i := 1; i <= len(runes); i++ {
+			res = append(res, string(runes[:i]))
+		}
+	}
+	return res
+}
+
+// saisiesBench - ce qu'un utilisateur tape: un nom, une faute de frappe, un membre, une ville, une annee
+var saisiesBench = frappes("pink floyd", "pink floid", "freddie mercury", "sao paulo", "1975")
+
+func TestCandidats(t *testing.T) {
+	ix := indexBench()
+	// ce que le parcours de tout le dictionnaire trouvait: chaque mot de la requete est dans un
+	// mot de l'entree (au debut du mot seulement en dessous de 3 caracteres, y'a pas de trigramme)
+	for _, q := range append(saisiesBench, "loyd", "ercur", "aulo", "pink loyd", "olo", "zzz") {
+		candidats := ix.candidats(q)
+		for idx, e := range ix.entrees {
+			trouve := true
+			for _, motQ := range texte.Mots(q) {
+				dedans := false
+				for _, mot := range texte.Mots(e.normalise) {
+					if strings.HasPrefix(mot, motQ) || (len([]rune(motQ)) >= 3 && strings.Contains(mot, motQ)) {
+						dedans = true
+						break
+					}
+				}
+				trouve = trouve && dedans
+			}
+			if trouve && !candidats[idx] {
+				t.Errorf("%q: %q manque dans les candidats", q, e.Valeur)
+			}
+		}
+	}
+
+	// le milieu d'un mot passe par les trigrammes
+	if res := ix.Chercher("loyd"); len(res) == 0 || !strings.HasPrefix(res[0].Valeur, "Pink Floyd") {
+		t.Errorf("loyd: %v", res[:min(len(res), 3)])
+	}
+}
+
+func BenchmarkConstruire(b *testing.B) {
+	artistes, locData, relData := jeuBench(tailleAPI * facteurBench)
+	for b.Loop() {
+		Construire(artistes, locData, relData)
+	}
+}
+
+// BenchmarkChercher - une recherche par frappe (le cache de la derniere requete sert pas,
+// chaque frappe change la requete)
+func BenchmarkChercher(b *testing.B) {
+	ix := indexBench()
+	i := 0
+	for b.Loop() {
+		ix.Chercher(saisiesBench[i%len(saisiesBench)])
+		i++
+	}
+}
+
+// BenchmarkSuggestionsEtFiltrage - tout ce que l'accueil demande a l'index a chaque frappe:
+// les suggestions, les artistes a garder dans la grille et la raison affichee sur chaque card
+func BenchmarkSuggestionsEtFiltrage(b *testing.B) {
+	ix := indexBench()
+	i := 0
+	for b.Loop() {
+		saisie := saisiesBench[i%len(saisiesBench)]
+		i++
+
+		resultats := ix.Chercher(saisie)
+		suggestions := make([]models.SuggestionRecherche, 0, 10)
+		for _, r := range resultats[:min(len(resultats), 10)] {
+			suggestions = append(suggestions, r.Suggestion)
+		}
+		gardes := ix.ArtistesPour(saisie)
+		raisons := ix.MeilleurParArtiste(saisie)
+		if len(raisons) != len(gardes) {
+			b.Fatalf("%q: %d artistes gardes mais %d raisons", saisie, len(gardes), len(raisons))
+		}
+	}
+}
+
+// BenchmarkArtistesPourType - le filtrage d'un champ de la recherche avancee (member:, city:...)
+func BenchmarkArtistesPourType(b *testing.B) {
+	ix := indexBench()
+	i := 0
+	for b.Loop() {
+		ix.ArtistesPour(saisiesBench[i%len(saisiesBench)], TypeMembre, TypeLieu)
+		i++
+	}
+}
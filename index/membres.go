@@ -0,0 +1,71 @@
+package index
+
+import (
+	"strings"
+
+	"groupie-tracker/models"
+	"groupie-tracker/texte"
+)
+
+// membres.go - l'index des membres: pour chaque personne, tous les groupes ou elle apparait
+// dans l'API les membres c'est juste des strings dans chaque artiste, donc on les regroupe
+// par nom normalise ("Freddie Mercury" et "freddie  mercury" c'est la meme personne)
+
+// Membre - une personne et ses groupes
+type Membre struct {
+	Cle      string // le nom normalise, c'est ce qui identifie le membre
+	Nom      string // le nom affiche (la premiere orthographe rencontree)
+	Artistes []int  // les IDs des groupes, dans l'ordre de l'API
+}
+
+// IndexMembres - tous les membres connus
+type IndexMembres struct {
+	parCle map[string]*Membre
+}
+
+// CleMembre - la cle d'un nom de membre
+func CleMembre(nom string) string {
+	return texte.Normaliser(nom)
+}
+
+// ConstruireMembres - regroupe les membres de tous les artistes
+func ConstruireMembres(artistes []models.Artiste) *IndexMembres {
+	im := &IndexMembres{parCle: make(map[string]*Membre)}
+	for _, artiste := range artistes {
+		for _, nom := range artiste.Membres {
+			nom = strings.TrimSpace(nom)
+			cle := CleMembre(nom)
+			if cle == "" {
+				continue
+			}
+			m, ok := im.parCle[cle]
+			if !ok {
+				m = &Membre{Cle: cle, Nom: nom}
+				im.parCle[cle] = m
+			}
+			// un meme nom peut apparaitre deux fois dans un groupe, on le compte qu'une fois
+			if len(m.Artistes) == 0 || m.Artistes[len(m.Artistes)-1] != artiste.ID {
+				m.Artistes = append(m.Artistes, artiste.ID)
+			}
+		}
+	}
+	return im
+}
+
+// Membre - le membre qui porte ce nom (n'importe quelle orthographe, ca passe par la cle)
+func (im *IndexMembres) Membre(nom string) (Membre, bool) {
+	m, ok := im.parCle[CleMembre(nom)]
+	if !ok {
+		return Membre{}, false
+	}
+	return *m, true
+}
+
+// NbGroupes - dans combien de groupes joue ce membre (0 si on le connait pas)
+func (im *IndexMembres) NbGroupes(nom string) int {
+	m, ok := im.parCle[CleMembre(nom)]
+	if !ok {
+		return 0
+	}
+	return len(m.Artistes)
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"os"
+
 	"groupie-tracker/gui"
 )
 
 // main.go - le point d'entree de l'application Groupie Tracker
 // on lance juste l'interface graphique, c'est elle qui gere tout le reste
+// les arguments servent a ouvrir l'app direct sur une page (groupie://artist/42, voir gui/liens.go)
 
 func main() {
 	// c'est parti mon kiki 🎵
-	gui.LancerApp()
+	gui.LancerApp(os.Args[1:])
 }
@@ -23,6 +23,11 @@ type Relation struct {
 	DatesLocations map[string][]string `json:"datesLocations"`
 }
 
+// IndexRelations - la reponse de l'API /relation avec les concerts de tous les artistes
+type IndexRelations struct {
+	Index []Relation `json:"index"`
+}
+
 // IndexLocations - la reponse de l'API /locations qui contient tous les lieux
 type IndexLocations struct {
 	Index []LocationData `json:"index"`
@@ -47,4 +52,5 @@ type SuggestionRecherche struct {
 	Texte     string // le texte a afficher genre "Phil Collins"
 	Type      string // le type: "artist/band", "member", "location", "first album date", "creation date"
 	ArtisteID int    // l'id de l'artiste correspondant pour pouvoir naviguer
+	Cle       string // pour un membre: son nom, pour un lieu: le lieu de l'API ("london-uk"), c'est leur page qu'on ouvre
 }
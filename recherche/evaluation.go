@@ -0,0 +1,231 @@
+package recherche
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"groupie-tracker/models"
+	"groupie-tracker/texte"
+)
+
+// evaluation.go - evalue l'AST sur un artiste
+// on passe par une Fiche qui regroupe l'artiste et ses concerts pour avoir tout sous la main
+
+// Fiche - tout ce qu'on sait d'un artiste pour evaluer une requete
+type Fiche struct {
+	Artiste  models.Artiste
+	Lieux    []string            // les lieux bruts de l'API genre "seattle-usa"
+	Concerts map[string][]string // lieu -> dates, depuis la relation
+
+	// ce que l'utilisateur a range lui-meme (vide si l'appelant les connait pas)
+	Collections []string
+	Tags        []string
+	Note        string // la note perso en markdown
+	Etoiles     int    // de 1 a 5, 0 si pas note
+}
+
+// Predicat - la requete compilee, dit si un artiste matche
+type Predicat func(f Fiche) bool
+
+// Compiler - parse la requete et renvoie le predicat correspondant
+// une requete vide matche tout le monde
+func Compiler(requete string) (Predicat, error) {
+	n, err := Analyser(requete)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return func(Fiche) bool { return true }, nil
+	}
+	return n.Evaluer, nil
+}
+
+// MoteurTexte - ce qui sait donner d'un coup les artistes qui matchent un texte libre
+// (en pratique c'est l'index de recherche, ca evite de tout rescanner artiste par artiste)
+type MoteurTexte func(texte string) map[int]bool
+
+// CompilerAvec - comme Compiler, mais les morceaux de texte libre passent par le moteur
+// les champs (member:, concerts>...) sont toujours evalues sur la fiche
+func CompilerAvec(requete string, moteur MoteurTexte) (Predicat, error) {
+	n, err := Analyser(requete)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return func(Fiche) bool { return true }, nil
+	}
+	return lier(n, moteur).Evaluer, nil
+}
+
+// noeudEnsemble - un texte libre deja resolu par le moteur: juste un ensemble d'IDs
+type noeudEnsemble struct{ ids map[int]bool }
+
+func (n noeudEnsemble) Evaluer(f Fiche) bool { return n.ids[f.Artiste.ID] }
+
+// lier - remplace les noeuds de texte libre par le resultat du moteur (une seule fois par requete)
+func lier(n Noeud, moteur MoteurTexte) Noeud {
+	switch t := n.(type) {
+	case noeudEt:
+		return noeudEt{lier(t.gauche, moteur), lier(t.droite, moteur)}
+	case noeudOu:
+		return noeudOu{lier(t.gauche, moteur), lier(t.droite, moteur)}
+	case noeudNon:
+		return noeudNon{lier(t.enfant, moteur)}
+	case noeudTexte:
+		return noeudEnsemble{moteur(t.texte)}
+	default:
+		return n
+	}
+}
+
+// AnneeAlbum - parse l'annee depuis le format "DD-MM-YYYY" du premier album, 0 si on y arrive pas
+func AnneeAlbum(dateStr string) int {
+	parts := strings.Split(dateStr, "-")
+	if len(parts) == 3 {
+		annee, err := strconv.Atoi(parts[2])
+		if err == nil {
+			return annee
+		}
+	}
+	return 0
+}
+
+// NbConcerts - le nombre total de dates de concert
+func (f Fiche) NbConcerts() int {
+	nb := 0
+	for _, dates := range f.Concerts {
+		nb += len(dates)
+	}
+	return nb
+}
+
+func (n noeudEt) Evaluer(f Fiche) bool  { return n.gauche.Evaluer(f) && n.droite.Evaluer(f) }
+func (n noeudOu) Evaluer(f Fiche) bool  { return n.gauche.Evaluer(f) || n.droite.Evaluer(f) }
+func (n noeudNon) Evaluer(f Fiche) bool { return !n.enfant.Evaluer(f) }
+
+// la recherche libre: nom, membres, lieux, date de creation, premier album, dates de concert
+// (les memes choses que les suggestions) le nom, les membres et les lieux tolerent les fautes de frappe
+func (n noeudTexte) Evaluer(f Fiche) bool {
+	art := f.Artiste
+	if Score(n.texte, art.Nom) > 0 {
+		return true
+	}
+	for _, m := range art.Membres {
+		if Score(n.texte, m) > 0 {
+			return true
+		}
+	}
+	for _, lieu := range f.Lieux {
+		if Score(n.texte, strings.ReplaceAll(strings.ReplaceAll(lieu, "_", " "), "-", ", ")) > 0 {
+			return true
+		}
+	}
+	if texte.Contient(fmt.Sprintf("%d", art.DateCreation), n.texte) {
+		return true
+	}
+	if texte.Contient(art.PremierAlbum, n.texte) {
+		return true
+	}
+	for _, dates := range f.Concerts {
+		for _, d := range dates {
+			if texte.Contient(strings.TrimPrefix(d, "*"), n.texte) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// correspond - ':' = contient, '=' = egal, toujours sans tenir compte de la casse ni des accents
+func (n noeudChampTexte) correspond(s string) bool {
+	s = texte.Normaliser(s)
+	valeur := texte.Normaliser(n.valeur)
+	if n.exact {
+		return s == valeur
+	}
+	return strings.Contains(s, valeur)
+}
+
+func (n noeudChampTexte) Evaluer(f Fiche) bool {
+	switch n.champ {
+	case ChampNom:
+		return n.correspond(f.Artiste.Nom)
+
+	case ChampMembre:
+		for _, m := range f.Artiste.Membres {
+			if n.correspond(m) {
+				return true
+			}
+		}
+
+	case ChampPays:
+		// pour le pays on compare toujours en entier, sinon "uk" matcherait "ukraine"
+		valeur := texte.Normaliser(strings.ReplaceAll(n.valeur, "_", " "))
+		for _, lieu := range f.Lieux {
+			idx := strings.LastIndex(lieu, "-")
+			pays := texte.Normaliser(strings.ReplaceAll(lieu[idx+1:], "_", " "))
+			if pays == valeur {
+				return true
+			}
+		}
+
+	case ChampLieu:
+		// "city:london" cherche dans "london uk", "city=london" veut la ville exacte
+		for _, lieu := range f.Lieux {
+			propre := strings.ReplaceAll(strings.ReplaceAll(lieu, "_", " "), "-", " ")
+			if n.exact {
+				if idx := strings.LastIndex(lieu, "-"); idx != -1 {
+					propre = strings.ReplaceAll(lieu[:idx], "_", " ")
+				}
+			}
+			if n.correspond(propre) {
+				return true
+			}
+		}
+
+	case ChampCollection:
+		for _, c := range f.Collections {
+			if n.correspond(c) {
+				return true
+			}
+		}
+
+	case ChampTag:
+		for _, t := range f.Tags {
+			if n.correspond(t) {
+				return true
+			}
+		}
+
+	case ChampNote:
+		return f.Note != "" && n.correspond(f.Note)
+
+	case ChampDate:
+		for _, dates := range f.Concerts {
+			for _, d := range dates {
+				if n.correspond(strings.TrimPrefix(d, "*")) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (n noeudChampNombre) Evaluer(f Fiche) bool {
+	var valeur int
+	switch n.champ {
+	case ChampCreation:
+		valeur = f.Artiste.DateCreation
+	case ChampAlbum:
+		valeur = AnneeAlbum(f.Artiste.PremierAlbum)
+	case ChampNbMembres:
+		valeur = len(f.Artiste.Membres)
+	case ChampNbConcerts:
+		valeur = f.NbConcerts()
+	case ChampEtoiles:
+		valeur = f.Etoiles
+	}
+	return valeur >= n.min && valeur <= n.max
+}
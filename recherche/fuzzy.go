@@ -0,0 +1,175 @@
+package recherche
+
+import (
+	"strings"
+	"unicode"
+
+	"groupie-tracker/texte"
+)
+
+// fuzzy.go - la recherche approximative, pour que "pink floid" ou "beyonse" trouvent quand meme
+// on donne un score a chaque texte: plus c'est haut plus c'est pertinent, 0 = ca matche pas
+// l'ordre des paliers: egal > commence par > un mot commence par > contient > a peu pres (fautes de frappe)
+
+// les paliers de score, assez espaces pour que les bonus restent dans leur palier
+const (
+	ScoreExact    = 1000
+	ScorePrefixe  = 900
+	ScoreDebutMot = 800
+	ScoreContient = 700
+	ScoreApprox   = 500
+)
+
+// Score - la pertinence de cible pour la requete, sans tenir compte des accents ni de la casse
+func Score(requete, cible string) int {
+	return ScoreNormalise(texte.Normaliser(requete), texte.Normaliser(cible))
+}
+
+// ScoreNormalise - pareil que Score mais les deux textes sont deja passes par texte.Normaliser
+// (l'index normalise tout d'avance, pas la peine de le refaire a chaque frappe)
+func ScoreNormalise(q, c string) int {
+	if q == "" || c == "" {
+		return 0
+	}
+
+	// petit bonus pour les cibles courtes: "queen" avant "queens of the stone age"
+	bonus := 50 - len([]rune(c))
+	if bonus < 0 {
+		bonus = 0
+	}
+
+	switch {
+	case c == q:
+		return ScoreExact
+	case strings.HasPrefix(c, q):
+		return ScorePrefixe + bonus
+	case debutDeMot(c, q):
+		return ScoreDebutMot + bonus
+	case strings.Contains(c, q):
+		return ScoreContient + bonus
+	}
+
+	// pas de fautes de frappe sur les nombres, sinon 1970 trouverait 1971
+	if !contientLettre(q) {
+		return 0
+	}
+	typos := typosPermises(q)
+	if typos == 0 {
+		return 0
+	}
+
+	// on compare la requete a chaque groupe de mots de la cible qui a le meme nombre de mots
+	// (ou a son debut, pour quand on est en train de taper)
+	runesQ := []rune(q)
+	motsQ := strings.Fields(q)
+	motsC := strings.Fields(c)
+	meilleure := -1
+	for i := 0; i+len(motsQ) <= len(motsC); i++ {
+		fenetre := []rune(strings.Join(motsC[i:i+len(motsQ)], " "))
+		if len(fenetre)+typos < len(runesQ) {
+			continue // trop court, meme avec toutes les fautes permises
+		}
+		d := distance(runesQ, fenetre)
+		if meilleure == -1 || d < meilleure {
+			meilleure = d
+		}
+	}
+	if meilleure == -1 || meilleure > typos {
+		return 0
+	}
+
+	// a nombre de fautes egal, les trigrammes communs departagent
+	return ScoreApprox - 100*meilleure + int(50*similariteTrigrammes(q, c))
+}
+
+// typosPermises - rien en dessous de 4 caracteres, une faute jusqu'a 6, deux au dela
+func typosPermises(q string) int {
+	switch n := len([]rune(q)); {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// debutDeMot - un des mots de c (ou une suite de mots) commence par q
+func debutDeMot(c, q string) bool {
+	precedente := ' '
+	for i, r := range c {
+		if !unicode.IsLetter(precedente) && !unicode.IsDigit(precedente) && strings.HasPrefix(c[i:], q) {
+			return true
+		}
+		precedente = r
+	}
+	return false
+}
+
+func contientLettre(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// distance - la distance d'edition entre la requete a et le debut de b, en runes
+// c'est Levenshtein + les inversions de deux lettres ("pnik" -> "pink" = 1 faute)
+// on prend le meilleur prefixe de b, comme ca "pink flo" est a 0 faute de "pink floyd"
+// (trois lignes qui tournent au lieu de toute la matrice, ca alloue beaucoup moins)
+func distance(a, b []rune) int {
+	avantDerniere := make([]int, len(b)+1)
+	derniere := make([]int, len(b)+1)
+	courante := make([]int, len(b)+1)
+	for j := range derniere {
+		derniere[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		courante[0] = i
+		for j := 1; j <= len(b); j++ {
+			cout := 1
+			if a[i-1] == b[j-1] {
+				cout = 0
+			}
+			courante[j] = min(derniere[j]+1, courante[j-1]+1, derniere[j-1]+cout)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				courante[j] = min(courante[j], avantDerniere[j-2]+1)
+			}
+		}
+		avantDerniere, derniere, courante = derniere, courante, avantDerniere
+	}
+
+	meilleure := derniere[0]
+	for _, d := range derniere[1:] {
+		meilleure = min(meilleure, d)
+	}
+	return meilleure
+}
+
+// Trigrammes - les morceaux de 3 caracteres du texte (avec des espaces autour)
+func Trigrammes(s string) map[string]bool {
+	runes := []rune("  " + s + " ")
+	res := make(map[string]bool)
+	for i := 0; i+3 <= len(runes); i++ {
+		res[string(runes[i:i+3])] = true
+	}
+	return res
+}
+
+// similariteTrigrammes - la proportion de trigrammes en commun (indice de Jaccard), entre 0 et 1
+func similariteTrigrammes(a, b string) float64 {
+	ta, tb := Trigrammes(a), Trigrammes(b)
+	communs := 0
+	for t := range ta {
+		if tb[t] {
+			communs++
+		}
+	}
+	total := len(ta) + len(tb) - communs
+	if total == 0 {
+		return 0
+	}
+	return float64(communs) / float64(total)
+}
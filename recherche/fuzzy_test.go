@@ -0,0 +1,81 @@
+package recherche
+
+import "testing"
+
+// fuzzy_test.go - le score sur des noms avec accents: la requete tapee sans accents
+// doit tomber dans le meme palier que si on avait tape le nom exact
+
+func TestScore(t *testing.T) {
+	cas := []struct {
+		requete, cible string
+		palier         int // ScoreExact, ScorePrefixe... ou 0 pour "matche pas"
+	}{
+		{"beyonce", "Beyoncé", ScoreExact},
+		{"BEYONCÉ", "beyonce", ScoreExact},
+		{"sao paulo", "São Paulo", ScoreExact},
+		{"lodz", "Łódź", ScoreExact},
+		{"strasse", "Straße", ScoreExact},
+		{"sao", "São Paulo", ScorePrefixe},
+		{"paulo", "São Paulo", ScoreDebutMot},
+		{"rhead", "Motörhead", ScoreContient},
+		{"aul", "São Paulo", ScoreContient},
+		{"motorhed", "Motörhead", ScoreApprox},
+		{"bejonce", "Beyoncé", ScoreApprox},
+		{"krakow", "Warszawa", 0},
+		{"1970", "1971", 0},
+	}
+	for _, c := range cas {
+		score := Score(c.requete, c.cible)
+		ok := false
+		switch c.palier {
+		case 0:
+			ok = score == 0
+		case ScoreExact:
+			ok = score == ScoreExact
+		case ScoreApprox:
+			ok = score > 0 && score < ScoreContient
+		default:
+			// le bonus des cibles courtes reste dans le palier
+			ok = score >= c.palier && score < c.palier+100
+		}
+		if !ok {
+			t.Errorf("Score(%q, %q) = %d, attendu dans le palier %d", c.requete, c.cible, score, c.palier)
+		}
+	}
+}
+
+func TestScoreFautesDeFrappe(t *testing.T) {
+	// les exemples de la demande: on trouve quand meme, dans le palier approximatif
+	cas := []struct{ requete, cible string }{
+		{"pink floid", "Pink Floyd"},
+		{"beyonse", "Beyoncé"},
+		{"pnik floyd", "Pink Floyd"},
+		{"freddie mercuri", "Freddie Mercury"},
+	}
+	for _, c := range cas {
+		if score := Score(c.requete, c.cible); score <= 0 || score >= ScoreContient {
+			t.Errorf("Score(%q, %q) = %d, attendu un score approximatif", c.requete, c.cible, score)
+		}
+	}
+
+	// et la bonne cible passe devant les autres
+	if Score("pink floid", "Pink Floyd") <= Score("pink floid", "Pink") {
+		t.Error(`"pink floid" trouve mieux Pink que Pink Floyd`)
+	}
+	if Score("beyonse", "Beyoncé") <= Score("beyonse", "Bee Gees") {
+		t.Error(`"beyonse" trouve mieux Bee Gees que Beyoncé`)
+	}
+}
+
+func TestScoreOrdrePertinence(t *testing.T) {
+	// du plus pertinent au moins pertinent: egal > commence par > un mot commence par > contient > a peu pres
+	cibles := []string{"Queen", "Queens of the Stone Age", "The Queen Band", "Aqueenx", "Quen"}
+	precedent := ScoreExact + 1
+	for _, cible := range cibles {
+		score := Score("queen", cible)
+		if score <= 0 || score >= precedent {
+			t.Errorf("Score(queen, %q) = %d, attendu entre 0 et %d", cible, score, precedent)
+		}
+		precedent = score
+	}
+}
@@ -0,0 +1,135 @@
+package recherche
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// lexer.go - decoupe le texte de la requete en jetons
+// genre `member:"Phil Collins" concerts>10` -> MOT(member) DEUXPOINTS CHAINE(Phil Collins) MOT(concerts) OP(>) MOT(10)
+
+// les types de jetons
+const (
+	JetonMot        = "mot"        // un mot tout seul: queen, 1970..1980, uk
+	JetonChaine     = "chaine"     // un texte entre guillemets
+	JetonDeuxPoints = "deuxpoints" // le ':' entre un champ et sa valeur
+	JetonOperateur  = "operateur"  // > < >= <= =
+	JetonMoins      = "moins"      // le '-' devant un terme pour l'exclure
+	JetonParenG     = "pareng"
+	JetonParenD     = "parend"
+	JetonFin        = "fin"
+)
+
+// Jeton - un morceau de la requete avec sa position (en runes) pour les messages d'erreur
+type Jeton struct {
+	Type     string
+	Texte    string
+	Position int
+}
+
+// ErreurRequete - une erreur de syntaxe qui pointe sur le jeton fautif
+type ErreurRequete struct {
+	Position int    // position en runes dans la requete
+	Jeton    string // le texte du jeton qui pose probleme
+	Message  string
+}
+
+func (e *ErreurRequete) Error() string {
+	if e.Jeton == "" {
+		return fmt.Sprintf("position %d: %s", e.Position+1, e.Message)
+	}
+	return fmt.Sprintf("position %d (%q): %s", e.Position+1, e.Jeton, e.Message)
+}
+
+// Souligner - renvoie la requete avec un ^ sous le jeton fautif, pratique pour l'afficher
+func (e *ErreurRequete) Souligner(requete string) string {
+	longueur := len([]rune(e.Jeton))
+	if longueur == 0 {
+		longueur = 1
+	}
+	return requete + "\n" + strings.Repeat(" ", e.Position) + strings.Repeat("^", longueur)
+}
+
+// finDeMot - les caracteres qui terminent un mot
+func finDeMot(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`:<>="()`, r)
+}
+
+// Citer - la valeur telle qu'il faut l'ecrire dans une requete pour qu'elle reste un seul jeton
+// un mot simple reste tel quel, sinon entre guillemets avec \" et \\ (l'inverse du lexer)
+func Citer(valeur string) string {
+	if valeur != "" && !strings.ContainsFunc(valeur, func(r rune) bool { return finDeMot(r) || r == '\\' }) {
+		return valeur
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(valeur) + `"`
+}
+
+// decouper - le lexer, fait main
+func decouper(requete string) ([]Jeton, error) {
+	runes := []rune(requete)
+	var jetons []Jeton
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			jetons = append(jetons, Jeton{Type: JetonParenG, Texte: "(", Position: i})
+			i++
+
+		case r == ')':
+			jetons = append(jetons, Jeton{Type: JetonParenD, Texte: ")", Position: i})
+			i++
+
+		case r == ':':
+			jetons = append(jetons, Jeton{Type: JetonDeuxPoints, Texte: ":", Position: i})
+			i++
+
+		case r == '<' || r == '>' || r == '=':
+			op := string(r)
+			if r != '=' && i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			jetons = append(jetons, Jeton{Type: JetonOperateur, Texte: op, Position: i})
+			i += len(op)
+
+		case r == '"':
+			// une chaine entre guillemets, on cherche le guillemet fermant
+			// \" et \\ dedans donnent un guillemet et un antislash (voir Citer)
+			debut := i
+			i++
+			var sb strings.Builder
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &ErreurRequete{Position: debut, Jeton: string(runes[debut:]), Message: "guillemet pas ferme"}
+			}
+			i++ // le guillemet fermant
+			jetons = append(jetons, Jeton{Type: JetonChaine, Texte: sb.String(), Position: debut})
+
+		case r == '-' && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '(') &&
+			i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			// un '-' collé devant un terme = exclusion (mais pas au milieu d'un mot genre 05-08-1967)
+			jetons = append(jetons, Jeton{Type: JetonMoins, Texte: "-", Position: i})
+			i++
+
+		default:
+			debut := i
+			for i < len(runes) && !finDeMot(runes[i]) {
+				i++
+			}
+			jetons = append(jetons, Jeton{Type: JetonMot, Texte: string(runes[debut:i]), Position: debut})
+		}
+	}
+
+	jetons = append(jetons, Jeton{Type: JetonFin, Position: len(runes)})
+	return jetons, nil
+}
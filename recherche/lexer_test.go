@@ -0,0 +1,65 @@
+package recherche
+
+import (
+	"reflect"
+	"testing"
+)
+
+// lexer_test.go - le decoupage en jetons, avec les positions en runes (pas en octets)
+
+func TestDecouper(t *testing.T) {
+	cas := []struct {
+		requete string
+		jetons  []Jeton
+	}{
+		{`member:"Phil Collins" concerts>=10`, []Jeton{
+			{JetonMot, "member", 0}, {JetonDeuxPoints, ":", 6}, {JetonChaine, "Phil Collins", 7},
+			{JetonMot, "concerts", 22}, {JetonOperateur, ">=", 30}, {JetonMot, "10", 32}, {JetonFin, "", 34},
+		}},
+		{`-(name:queen OR beyoncé)`, []Jeton{
+			{JetonMoins, "-", 0}, {JetonParenG, "(", 1}, {JetonMot, "name", 2}, {JetonDeuxPoints, ":", 6},
+			{JetonMot, "queen", 7}, {JetonMot, "OR", 13}, {JetonMot, "beyoncé", 16}, {JetonParenD, ")", 23}, {JetonFin, "", 24},
+		}},
+		// un tiret au milieu d'un mot c'est pas une exclusion
+		{`05-08-1967 a<b`, []Jeton{
+			{JetonMot, "05-08-1967", 0}, {JetonMot, "a", 11}, {JetonOperateur, "<", 12}, {JetonMot, "b", 13}, {JetonFin, "", 14},
+		}},
+		// \" et \\ dans une chaine
+		{`note:"il a dit \"ok\" c:\\x"`, []Jeton{
+			{JetonMot, "note", 0}, {JetonDeuxPoints, ":", 4}, {JetonChaine, `il a dit "ok" c:\x`, 5}, {JetonFin, "", 28},
+		}},
+		// un antislash devant autre chose reste tel quel
+		{`"a\b"`, []Jeton{{JetonChaine, `a\b`, 0}, {JetonFin, "", 5}}},
+	}
+	for _, c := range cas {
+		jetons, err := decouper(c.requete)
+		if err != nil {
+			t.Errorf("%s: %v", c.requete, err)
+			continue
+		}
+		if !reflect.DeepEqual(jetons, c.jetons) {
+			t.Errorf("%s:\n  obtenu  %v\n  attendu %v", c.requete, jetons, c.jetons)
+		}
+	}
+}
+
+func TestCiter(t *testing.T) {
+	cas := []struct{ valeur, cite string }{
+		{"queen", "queen"},
+		{"Phil Collins", `"Phil Collins"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`c:\x`, `"c:\\x"`},
+		{"(live)", `"(live)"`},
+		{"", `""`},
+	}
+	for _, c := range cas {
+		if got := Citer(c.valeur); got != c.cite {
+			t.Errorf("Citer(%q) = %s, attendu %s", c.valeur, got, c.cite)
+		}
+		// et le lexer redonne la valeur de depart, en un seul jeton
+		jetons, err := decouper(Citer(c.valeur))
+		if err != nil || len(jetons) != 2 || jetons[0].Texte != c.valeur {
+			t.Errorf("decouper(Citer(%q)) = %v, %v", c.valeur, jetons, err)
+		}
+	}
+}
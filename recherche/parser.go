@@ -0,0 +1,356 @@
+package recherche
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// parser.go - transforme les jetons en arbre (AST), fait main aussi
+//
+// la grammaire, du moins prioritaire au plus prioritaire:
+//
+//	ou       := et ("OR" et)*
+//	et       := non (["AND"] non)*        deux termes colles = AND
+//	non      := ("-" | "NOT") non | primaire
+//	primaire := "(" ou ")" | champ | texte
+//	champ    := MOT (":" | ">" | "<" | ">=" | "<=" | "=") (MOT | CHAINE)
+//	texte    := CHAINE | MOT MOT...       les mots qui se suivent forment une seule phrase
+
+// Noeud - un noeud de l'AST, il sait s'evaluer sur la fiche d'un artiste
+type Noeud interface {
+	Evaluer(f Fiche) bool
+}
+
+type noeudEt struct{ gauche, droite Noeud }
+type noeudOu struct{ gauche, droite Noeud }
+type noeudNon struct{ enfant Noeud }
+
+// noeudTexte - la recherche libre, comme avant (nom, membres, dates)
+type noeudTexte struct{ texte string }
+
+// noeudChampTexte - un champ texte genre member:"phil collins" ou country=uk
+type noeudChampTexte struct {
+	champ  string
+	exact  bool // '=' au lieu de ':'
+	valeur string
+}
+
+// noeudChampNombre - un champ numerique genre created:1970..1980 ou concerts>10
+// l'intervalle est inclusif des deux cotes
+type noeudChampNombre struct {
+	champ    string
+	min, max int
+}
+
+// les champs connus avec leurs alias, rangés selon que c'est du texte ou des nombres
+var champsTexte = map[string]string{
+	"name":       ChampNom,
+	"artist":     ChampNom,
+	"band":       ChampNom,
+	"member":     ChampMembre,
+	"country":    ChampPays,
+	"city":       ChampLieu,
+	"location":   ChampLieu,
+	"date":       ChampDate,
+	"tag":        ChampTag,
+	"collection": ChampCollection,
+	"list":       ChampCollection,
+	"note":       ChampNote,
+	"notes":      ChampNote,
+}
+
+var champsNombre = map[string]string{
+	"created":  ChampCreation,
+	"creation": ChampCreation,
+	"album":    ChampAlbum,
+	"members":  ChampNbMembres,
+	"concerts": ChampNbConcerts,
+	"rating":   ChampEtoiles,
+	"stars":    ChampEtoiles,
+}
+
+// les noms canoniques des champs
+const (
+	ChampNom        = "name"
+	ChampMembre     = "member"
+	ChampPays       = "country"
+	ChampLieu       = "city"
+	ChampDate       = "date"
+	ChampTag        = "tag"
+	ChampCollection = "collection"
+	ChampNote       = "note"
+	ChampCreation   = "created"
+	ChampAlbum      = "album"
+	ChampNbMembres  = "members"
+	ChampNbConcerts = "concerts"
+	ChampEtoiles    = "rating"
+)
+
+// les bornes "infinies" pour les intervalles ouverts genre created:..1980
+const (
+	minInfini = -1 << 31
+	maxInfini = 1<<31 - 1
+)
+
+// analyseur - l'etat du parser, on avance jeton par jeton
+type analyseur struct {
+	jetons []Jeton
+	pos    int
+}
+
+// Analyser - parse une requete et renvoie son AST
+// une requete vide renvoie nil (= tout matche)
+func Analyser(requete string) (Noeud, error) {
+	jetons, err := decouper(requete)
+	if err != nil {
+		return nil, err
+	}
+	a := &analyseur{jetons: jetons}
+	if a.courant().Type == JetonFin {
+		return nil, nil
+	}
+
+	n, err := a.parseOu()
+	if err != nil {
+		return nil, err
+	}
+	if j := a.courant(); j.Type != JetonFin {
+		if j.Type == JetonParenD {
+			return nil, erreurSur(j, "parenthese fermante en trop")
+		}
+		return nil, erreurSur(j, "jeton inattendu")
+	}
+	return n, nil
+}
+
+// TexteLibre - si la requete c'est juste du texte libre (pas de champ, pas d'operateur)
+// on renvoie ce texte, ca permet de garder l'ancien comportement pour les suggestions
+func TexteLibre(n Noeud) (string, bool) {
+	t, ok := n.(noeudTexte)
+	return t.texte, ok
+}
+
+// TextesLibres - tous les morceaux de texte libre de la requete, sauf ceux sous un NOT
+// (un artiste exclu par "-queen" n'a pas de raison d'etre affiche a cause de queen)
+func TextesLibres(n Noeud) []string {
+	switch t := n.(type) {
+	case noeudTexte:
+		return []string{t.texte}
+	case noeudEt:
+		return append(TextesLibres(t.gauche), TextesLibres(t.droite)...)
+	case noeudOu:
+		return append(TextesLibres(t.gauche), TextesLibres(t.droite)...)
+	default:
+		return nil
+	}
+}
+
+func erreurSur(j Jeton, message string) *ErreurRequete {
+	return &ErreurRequete{Position: j.Position, Jeton: j.Texte, Message: message}
+}
+
+func (a *analyseur) courant() Jeton {
+	return a.jetons[a.pos]
+}
+
+func (a *analyseur) suivant() Jeton {
+	if a.pos+1 < len(a.jetons) {
+		return a.jetons[a.pos+1]
+	}
+	return a.jetons[len(a.jetons)-1]
+}
+
+func (a *analyseur) avancer() Jeton {
+	j := a.jetons[a.pos]
+	if a.pos < len(a.jetons)-1 {
+		a.pos++
+	}
+	return j
+}
+
+// estMotCle - OR / AND / NOT en majuscules, comme dans les moteurs de recherche
+func estMotCle(j Jeton, mot string) bool {
+	return j.Type == JetonMot && j.Texte == mot
+}
+
+// debutDeChamp - un mot directement suivi (sans espace) de ':' ou d'un operateur
+func (a *analyseur) debutDeChamp() bool {
+	j, s := a.courant(), a.suivant()
+	return j.Type == JetonMot && (s.Type == JetonDeuxPoints || s.Type == JetonOperateur) &&
+		s.Position == j.Position+len([]rune(j.Texte))
+}
+
+func (a *analyseur) parseOu() (Noeud, error) {
+	gauche, err := a.parseEt()
+	if err != nil {
+		return nil, err
+	}
+	for estMotCle(a.courant(), "OR") {
+		a.avancer()
+		droite, err := a.parseEt()
+		if err != nil {
+			return nil, err
+		}
+		gauche = noeudOu{gauche, droite}
+	}
+	return gauche, nil
+}
+
+func (a *analyseur) parseEt() (Noeud, error) {
+	gauche, err := a.parseNon()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		j := a.courant()
+		if j.Type == JetonFin || j.Type == JetonParenD || estMotCle(j, "OR") {
+			return gauche, nil
+		}
+		if estMotCle(j, "AND") {
+			a.avancer()
+		}
+		droite, err := a.parseNon()
+		if err != nil {
+			return nil, err
+		}
+		gauche = noeudEt{gauche, droite}
+	}
+}
+
+func (a *analyseur) parseNon() (Noeud, error) {
+	j := a.courant()
+	if j.Type == JetonMoins || estMotCle(j, "NOT") {
+		a.avancer()
+		enfant, err := a.parseNon()
+		if err != nil {
+			return nil, err
+		}
+		return noeudNon{enfant}, nil
+	}
+	return a.parsePrimaire()
+}
+
+func (a *analyseur) parsePrimaire() (Noeud, error) {
+	j := a.courant()
+	switch j.Type {
+	case JetonParenG:
+		a.avancer()
+		n, err := a.parseOu()
+		if err != nil {
+			return nil, err
+		}
+		if a.courant().Type != JetonParenD {
+			return nil, erreurSur(j, "parenthese pas fermee")
+		}
+		a.avancer()
+		return n, nil
+
+	case JetonParenD:
+		return nil, erreurSur(j, "parenthese fermante en trop")
+
+	case JetonChaine:
+		a.avancer()
+		return noeudTexte{texte: j.Texte}, nil
+
+	case JetonMot:
+		if estMotCle(j, "AND") || estMotCle(j, "OR") {
+			return nil, erreurSur(j, "il manque un terme avant "+j.Texte)
+		}
+		if a.debutDeChamp() {
+			return a.parseChamp()
+		}
+		// les mots qui se suivent forment une seule phrase ("pink floyd")
+		var mots []string
+		for a.courant().Type == JetonMot && !a.debutDeChamp() &&
+			!estMotCle(a.courant(), "AND") && !estMotCle(a.courant(), "OR") && !estMotCle(a.courant(), "NOT") {
+			mots = append(mots, a.avancer().Texte)
+		}
+		return noeudTexte{texte: strings.Join(mots, " ")}, nil
+
+	case JetonDeuxPoints, JetonOperateur:
+		return nil, erreurSur(j, "il manque le nom du champ avant "+j.Texte)
+
+	default:
+		return nil, erreurSur(j, "il manque un terme a la fin")
+	}
+}
+
+// parseChamp - champ + operateur + valeur, on verifie tout de suite que ca a du sens
+func (a *analyseur) parseChamp() (Noeud, error) {
+	jChamp := a.avancer()
+	jOp := a.avancer()
+	jValeur := a.courant()
+	if jValeur.Type != JetonMot && jValeur.Type != JetonChaine {
+		return nil, erreurSur(jValeur, "il manque la valeur apres "+jChamp.Texte+jOp.Texte)
+	}
+	a.avancer()
+
+	nom := strings.ToLower(jChamp.Texte)
+	if champ, ok := champsTexte[nom]; ok {
+		switch jOp.Texte {
+		case ":":
+			return noeudChampTexte{champ: champ, valeur: jValeur.Texte}, nil
+		case "=":
+			return noeudChampTexte{champ: champ, exact: true, valeur: jValeur.Texte}, nil
+		default:
+			return nil, erreurSur(jOp, "l'operateur "+jOp.Texte+" marche que sur les champs numeriques")
+		}
+	}
+
+	champ, ok := champsNombre[nom]
+	if !ok {
+		return nil, erreurSur(jChamp, "champ inconnu (name, member, country, city, date, tag, collection, note, created, album, members, concerts, rating)")
+	}
+
+	// avec ':' on accepte un nombre ou un intervalle N..M (un des deux cotes peut manquer)
+	if jOp.Texte == ":" && strings.Contains(jValeur.Texte, "..") {
+		parties := strings.SplitN(jValeur.Texte, "..", 2)
+		min, max := minInfini, maxInfini
+		var err error
+		if parties[0] != "" {
+			if min, err = lireNombre(jValeur, parties[0], "debut d'intervalle pas un nombre"); err != nil {
+				return nil, err
+			}
+		}
+		if parties[1] != "" {
+			if max, err = lireNombre(jValeur, parties[1], "fin d'intervalle pas un nombre"); err != nil {
+				return nil, err
+			}
+		}
+		if min > max {
+			return nil, erreurSur(jValeur, "intervalle a l'envers")
+		}
+		return noeudChampNombre{champ: champ, min: min, max: max}, nil
+	}
+
+	n, err := lireNombre(jValeur, jValeur.Texte, "il faut un nombre pour "+nom)
+	if err != nil {
+		return nil, err
+	}
+	switch jOp.Texte {
+	case ">":
+		return noeudChampNombre{champ: champ, min: n + 1, max: maxInfini}, nil
+	case ">=":
+		return noeudChampNombre{champ: champ, min: n, max: maxInfini}, nil
+	case "<":
+		return noeudChampNombre{champ: champ, min: minInfini, max: n - 1}, nil
+	case "<=":
+		return noeudChampNombre{champ: champ, min: minInfini, max: n}, nil
+	default: // ':' et '='
+		return noeudChampNombre{champ: champ, min: n, max: n}, nil
+	}
+}
+
+// lireNombre - un nombre de la requete, strictement entre les bornes "infinies"
+// (sinon concerts>9223372036854775807 deborderait sur n+1 et matcherait tout le monde)
+func lireNombre(j Jeton, s, message string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if errors.Is(err, strconv.ErrRange) || (err == nil && (n <= minInfini || n >= maxInfini)) {
+		return 0, erreurSur(j, "nombre trop grand")
+	}
+	if err != nil {
+		return 0, erreurSur(j, message)
+	}
+	return n, nil
+}
@@ -0,0 +1,216 @@
+package recherche
+
+import (
+	"reflect"
+	"testing"
+
+	"groupie-tracker/models"
+)
+
+// parser_test.go - l'arbre produit pour chaque requete, et les erreurs qui pointent sur le bon jeton
+
+// raccourcis pour ecrire les arbres attendus
+func nom(v string) Noeud { return noeudChampTexte{champ: ChampNom, valeur: v} }
+
+func TestAnalyserPriorites(t *testing.T) {
+	a, b, c := nom("a"), nom("b"), nom("c")
+	cas := []struct {
+		requete string
+		arbre   Noeud
+	}{
+		// AND (explicite ou implicite) passe avant OR
+		{`name:a OR name:b name:c`, noeudOu{a, noeudEt{b, c}}},
+		{`name:a AND name:b OR name:c`, noeudOu{noeudEt{a, b}, c}},
+		{`name:a OR name:b OR name:c`, noeudOu{noeudOu{a, b}, c}},
+		// NOT et - passent avant AND et OR
+		{`NOT name:a name:b`, noeudEt{noeudNon{a}, b}},
+		{`-name:a OR name:b`, noeudOu{noeudNon{a}, b}},
+		{`name:a -name:b`, noeudEt{a, noeudNon{b}}},
+		{`NOT -name:a`, noeudNon{noeudNon{a}}},
+		// les parentheses changent l'ordre
+		{`(name:a OR name:b) name:c`, noeudEt{noeudOu{a, b}, c}},
+		{`name:a (name:b OR name:c)`, noeudEt{a, noeudOu{b, c}}},
+		{`NOT (name:a OR name:b)`, noeudNon{noeudOu{a, b}}},
+		{`-(name:a name:b)`, noeudNon{noeudEt{a, b}}},
+	}
+	for _, c := range cas {
+		arbre, err := Analyser(c.requete)
+		if err != nil {
+			t.Errorf("%s: %v", c.requete, err)
+			continue
+		}
+		if !reflect.DeepEqual(arbre, c.arbre) {
+			t.Errorf("%s:\n  obtenu  %#v\n  attendu %#v", c.requete, arbre, c.arbre)
+		}
+	}
+}
+
+func TestAnalyserTexteLibre(t *testing.T) {
+	cas := []struct {
+		requete string
+		arbre   Noeud
+	}{
+		{``, nil},
+		{`queen`, noeudTexte{"queen"}},
+		// les mots qui se suivent = une seule phrase
+		{`pink floyd`, noeudTexte{"pink floyd"}},
+		{`"pink floyd"`, noeudTexte{"pink floyd"}},
+		{`05-08-1967`, noeudTexte{"05-08-1967"}},
+		// du texte libre a cote d'un champ = AND
+		{`queen country:uk`, noeudEt{noeudTexte{"queen"}, noeudChampTexte{champ: ChampPays, valeur: "uk"}}},
+		{`queen -floyd`, noeudEt{noeudTexte{"queen"}, noeudNon{noeudTexte{"floyd"}}}},
+	}
+	for _, c := range cas {
+		arbre, err := Analyser(c.requete)
+		if err != nil {
+			t.Errorf("%s: %v", c.requete, err)
+			continue
+		}
+		if !reflect.DeepEqual(arbre, c.arbre) {
+			t.Errorf("%s:\n  obtenu  %#v\n  attendu %#v", c.requete, arbre, c.arbre)
+		}
+	}
+
+	if texte, ok := TexteLibre(noeudTexte{"pink floyd"}); !ok || texte != "pink floyd" {
+		t.Errorf("TexteLibre = %q, %v", texte, ok)
+	}
+	if _, ok := TexteLibre(nom("queen")); ok {
+		t.Error("TexteLibre accepte un champ")
+	}
+}
+
+func TestAnalyserChamps(t *testing.T) {
+	cas := []struct {
+		requete string
+		arbre   Noeud
+	}{
+		// les alias des champs texte
+		{`name:queen`, noeudChampTexte{champ: ChampNom, valeur: "queen"}},
+		{`artist:queen`, noeudChampTexte{champ: ChampNom, valeur: "queen"}},
+		{`band:queen`, noeudChampTexte{champ: ChampNom, valeur: "queen"}},
+		{`NAME:queen`, noeudChampTexte{champ: ChampNom, valeur: "queen"}},
+		{`member:"Phil Collins"`, noeudChampTexte{champ: ChampMembre, valeur: "Phil Collins"}},
+		{`country:uk`, noeudChampTexte{champ: ChampPays, valeur: "uk"}},
+		{`city:london`, noeudChampTexte{champ: ChampLieu, valeur: "london"}},
+		{`location:london`, noeudChampTexte{champ: ChampLieu, valeur: "london"}},
+		{`date:1997`, noeudChampTexte{champ: ChampDate, valeur: "1997"}},
+		{`tag:live`, noeudChampTexte{champ: ChampTag, valeur: "live"}},
+		{`collection:rock`, noeudChampTexte{champ: ChampCollection, valeur: "rock"}},
+		{`list:rock`, noeudChampTexte{champ: ChampCollection, valeur: "rock"}},
+		{`note:vu`, noeudChampTexte{champ: ChampNote, valeur: "vu"}},
+		{`notes:vu`, noeudChampTexte{champ: ChampNote, valeur: "vu"}},
+		{`city=london`, noeudChampTexte{champ: ChampLieu, exact: true, valeur: "london"}},
+
+		// les alias des champs numeriques
+		{`created:1970`, noeudChampNombre{ChampCreation, 1970, 1970}},
+		{`creation:1970`, noeudChampNombre{ChampCreation, 1970, 1970}},
+		{`album:1973`, noeudChampNombre{ChampAlbum, 1973, 1973}},
+		{`members:4`, noeudChampNombre{ChampNbMembres, 4, 4}},
+		{`concerts:10`, noeudChampNombre{ChampNbConcerts, 10, 10}},
+		{`rating:5`, noeudChampNombre{ChampEtoiles, 5, 5}},
+		{`stars:5`, noeudChampNombre{ChampEtoiles, 5, 5}},
+
+		// les intervalles, un cote peut manquer
+		{`created:1970..1980`, noeudChampNombre{ChampCreation, 1970, 1980}},
+		{`created:..1980`, noeudChampNombre{ChampCreation, minInfini, 1980}},
+		{`created:1970..`, noeudChampNombre{ChampCreation, 1970, maxInfini}},
+		{`created:-5..5`, noeudChampNombre{ChampCreation, -5, 5}},
+
+		// les operateurs
+		{`concerts>10`, noeudChampNombre{ChampNbConcerts, 11, maxInfini}},
+		{`concerts>=10`, noeudChampNombre{ChampNbConcerts, 10, maxInfini}},
+		{`concerts<10`, noeudChampNombre{ChampNbConcerts, minInfini, 9}},
+		{`concerts<=10`, noeudChampNombre{ChampNbConcerts, minInfini, 10}},
+		{`concerts=10`, noeudChampNombre{ChampNbConcerts, 10, 10}},
+		{`rating>=4`, noeudChampNombre{ChampEtoiles, 4, maxInfini}},
+	}
+	for _, c := range cas {
+		arbre, err := Analyser(c.requete)
+		if err != nil {
+			t.Errorf("%s: %v", c.requete, err)
+			continue
+		}
+		if !reflect.DeepEqual(arbre, c.arbre) {
+			t.Errorf("%s:\n  obtenu  %#v\n  attendu %#v", c.requete, arbre, c.arbre)
+		}
+	}
+}
+
+func TestAnalyserErreurs(t *testing.T) {
+	cas := []struct {
+		requete   string
+		position  int
+		jeton     string
+		souligner string
+	}{
+		{`member:"phil`, 7, `"phil`, "member:\"phil\n       ^^^^^"},
+		{`foo:bar`, 0, "foo", "foo:bar\n^^^"},
+		// la position est en runes, pas en octets
+		{`beyoncé foo:bar`, 8, "foo", "beyoncé foo:bar\n        ^^^"},
+		{`created:1980..1970`, 8, "1980..1970", "created:1980..1970\n        ^^^^^^^^^^"},
+		{`created:19a0..1980`, 8, "19a0..1980", "created:19a0..1980\n        ^^^^^^^^^^"},
+		{`queen)`, 5, ")", "queen)\n     ^"},
+		{`)`, 0, ")", ")\n^"},
+		{`(queen`, 0, "(", "(queen\n^"},
+		{`member>3`, 6, ">", "member>3\n      ^"},
+		{`concerts>abc`, 9, "abc", "concerts>abc\n         ^^^"},
+		// un nombre trop grand deborderait sur n+1 / n-1
+		{`concerts>9223372036854775807`, 9, "9223372036854775807", "concerts>9223372036854775807\n         ^^^^^^^^^^^^^^^^^^^"},
+		{`concerts<-9223372036854775808`, 9, "-9223372036854775808", "concerts<-9223372036854775808\n         ^^^^^^^^^^^^^^^^^^^^"},
+		{`created:..99999999999999999999`, 8, "..99999999999999999999", "created:..99999999999999999999\n        ^^^^^^^^^^^^^^^^^^^^^^"},
+		// pas de jeton a la fin: un seul ^ apres la requete
+		{`name:`, 5, "", "name:\n     ^"},
+		{`OR queen`, 0, "OR", "OR queen\n^^"},
+	}
+	for _, c := range cas {
+		_, err := Analyser(c.requete)
+		e, ok := err.(*ErreurRequete)
+		if !ok {
+			t.Errorf("%s: erreur %v, attendu une *ErreurRequete", c.requete, err)
+			continue
+		}
+		if e.Position != c.position || e.Jeton != c.jeton {
+			t.Errorf("%s: position %d jeton %q, attendu %d %q (%s)", c.requete, e.Position, e.Jeton, c.position, c.jeton, e.Message)
+		}
+		if got := e.Souligner(c.requete); got != c.souligner {
+			t.Errorf("%s: Souligner =\n%s\nattendu\n%s", c.requete, got, c.souligner)
+		}
+	}
+}
+
+func TestCompilerSurFiches(t *testing.T) {
+	genesis := Fiche{
+		Artiste:  models.Artiste{Nom: "Genesis", Membres: []string{"Phil Collins", "Peter Gabriel"}, DateCreation: 1967, PremierAlbum: "07-03-1969"},
+		Lieux:    []string{"london-uk", "kiev-ukraine"},
+		Concerts: map[string][]string{"london-uk": make([]string, 11), "kiev-ukraine": {"01-01-2000"}},
+		Etoiles:  4,
+	}
+	queen := Fiche{
+		Artiste: models.Artiste{Nom: "Queen", Membres: []string{"Freddie Mercury"}, DateCreation: 1970, PremierAlbum: "13-07-1973"},
+		Lieux:   []string{"osaka-japan"},
+	}
+	cas := []struct {
+		requete        string
+		genesis, queen bool
+	}{
+		{`member:"phil collins" created:1960..1970 concerts>10`, true, false},
+		{`country:uk`, true, false},
+		{`country:ukraine -name:queen`, true, false},
+		{`name:queen OR created<1970`, true, true},
+		{`NOT name:queen`, true, false},
+		{`concerts>2147483646`, false, false},
+		{`rating>=4`, true, false},
+		{`album:1973`, false, true},
+		{`mercury`, false, true},
+	}
+	for _, c := range cas {
+		p, err := Compiler(c.requete)
+		if err != nil {
+			t.Errorf("%s: %v", c.requete, err)
+			continue
+		}
+		if got := [2]bool{p(genesis), p(queen)}; got != [2]bool{c.genesis, c.queen} {
+			t.Errorf("%s: genesis %v queen %v, attendu %v %v", c.requete, got[0], got[1], c.genesis, c.queen)
+		}
+	}
+}
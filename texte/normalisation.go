@@ -0,0 +1,95 @@
+package texte
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalisation.go - met les textes sous une forme comparable, c'est utilise partout
+// (recherche, suggestions, filtres, cles de lieux) pour que "Beyonce" = "Beyoncé"
+// et "Sao Paulo" = "São Paulo": minuscules (case folding), sans accents, espaces propres
+
+// pliage - le case folding Unicode, mieux que ToLower pour les cas tordus genre "ß" -> "ss"
+var pliage = cases.Fold()
+
+// lettresSpeciales - les lettres qui se decomposent pas en NFD mais qu'on veut quand meme
+// ramener a des lettres simples ("Motörhead" marche deja, mais pas "Sigur Rós Ø" ou "Łódź")
+var lettresSpeciales = strings.NewReplacer(
+	"ø", "o", "Ø", "o",
+	"æ", "ae", "Æ", "ae",
+	"œ", "oe", "Œ", "oe",
+	"ł", "l", "Ł", "l",
+	"đ", "d", "Đ", "d",
+	"ð", "d", "Ð", "d",
+	"þ", "th", "Þ", "th",
+	"ı", "i",
+)
+
+// Normaliser - decompose en NFD, vire les accents (les marques combinantes), plie la casse
+// et recolle les mots avec un seul espace
+func Normaliser(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range norm.NFD.String(lettresSpeciales.Replace(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return strings.Join(strings.Fields(pliage.String(sb.String())), " ")
+}
+
+// Contient - est-ce que s contient sous, sans tenir compte des accents ni de la casse
+func Contient(s, sous string) bool {
+	return strings.Contains(Normaliser(s), Normaliser(sous))
+}
+
+// Trouver - la position (en octets dans s) du premier passage de s qui correspond a sous,
+// sans tenir compte des accents ni de la casse; -1, -1 si y'en a pas
+// ca sert a surligner dans le texte d'origine ce qui a matche dans le texte normalise
+func Trouver(s, sous string) (debut, fin int) {
+	q := Normaliser(sous)
+	if q == "" {
+		return -1, -1
+	}
+
+	// on renormalise s rune par rune en notant d'ou vient chaque octet du resultat
+	var sb strings.Builder
+	var debuts, fins []int
+	espace := true // comme ca les espaces du debut sautent
+	for i, r := range s {
+		finRune := i + utf8.RuneLen(r)
+		morceau := " "
+		if unicode.IsSpace(r) {
+			if espace {
+				continue
+			}
+			espace = true
+		} else {
+			morceau = Normaliser(string(r))
+			espace = false
+		}
+		for k := 0; k < len(morceau); k++ {
+			debuts = append(debuts, i)
+			fins = append(fins, finRune)
+		}
+		sb.WriteString(morceau)
+	}
+
+	idx := strings.Index(sb.String(), q)
+	if idx == -1 {
+		return -1, -1
+	}
+	return debuts[idx], fins[idx+len(q)-1]
+}
+
+// Mots - decoupe un texte normalise en mots (tout ce qui est pas lettre ou chiffre separe)
+func Mots(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,83 @@
+package texte
+
+import "testing"
+
+// normalisation_test.go - des noms d'artistes et de villes de partout:
+// accents, ß, Ł / ł, ligatures, lettres nordiques, espaces bizarres
+
+func TestNormaliser(t *testing.T) {
+	cas := []struct {
+		entree, attendu string
+	}{
+		// accents
+		{"Beyoncé", "beyonce"},
+		{"São Paulo", "sao paulo"},
+		{"Motörhead", "motorhead"},
+		{"Sigur Rós", "sigur ros"},
+		{"Kraków", "krakow"},
+		{"Curaçao", "curacao"},
+		{"Zürich", "zurich"},
+		{"Ωμέγα", "ωμεγα"},
+		// ß plie en ss, dans les deux sens
+		{"Straße", "strasse"},
+		{"STRASSE", "strasse"},
+		// les lettres qui se decomposent pas en NFD
+		{"Łódź", "lodz"},
+		{"łódź", "lodz"},
+		{"Ærøskøbing", "aeroskobing"},
+		{"Þór", "thor"},
+		{"Đakovo", "dakovo"},
+		{"Œuvre", "oeuvre"},
+		// i turc avec ou sans point
+		{"İstanbul", "istanbul"},
+		{"ıstanbul", "istanbul"},
+		// les ecritures sans casse restent telles quelles
+		{"東京", "東京"},
+		// espaces au debut, a la fin, en double, insecables, tabulations
+		{"  São  Paulo\t", "sao paulo"},
+		{"", ""},
+	}
+	for _, c := range cas {
+		if got := Normaliser(c.entree); got != c.attendu {
+			t.Errorf("Normaliser(%q) = %q, attendu %q", c.entree, got, c.attendu)
+		}
+	}
+}
+
+func TestContient(t *testing.T) {
+	cas := []struct {
+		texte, sous string
+		attendu     bool
+	}{
+		{"Beyoncé Knowles", "BEYONCE", true},
+		{"sao_paulo-brazil", "São", true},
+		{"Łódź, Poland", "lodz", true},
+		{"Straße der Pariser Kommune", "strasse", true},
+		{"Motörhead", "motley", false},
+	}
+	for _, c := range cas {
+		if got := Contient(c.texte, c.sous); got != c.attendu {
+			t.Errorf("Contient(%q, %q) = %v, attendu %v", c.texte, c.sous, got, c.attendu)
+		}
+	}
+}
+
+// TestTrouver - les positions sont en octets dans le texte d'origine, accents compris
+func TestTrouver(t *testing.T) {
+	cas := []struct {
+		texte, sous string
+		debut, fin  int
+	}{
+		{"Beyoncé Knowles", "beyonce", 0, len("Beyoncé")},
+		{"Motörhead", "MOTOR", 0, len("Motör")},
+		{"Live in São Paulo", "sao paulo", len("Live in "), len("Live in São Paulo")},
+		{"Łódź", "odz", len("Ł"), len("Łódź")},
+		{"Queen", "abba", -1, -1},
+	}
+	for _, c := range cas {
+		debut, fin := Trouver(c.texte, c.sous)
+		if debut != c.debut || fin != c.fin {
+			t.Errorf("Trouver(%q, %q) = %d, %d, attendu %d, %d", c.texte, c.sous, debut, fin, c.debut, c.fin)
+		}
+	}
+}